@@ -2,30 +2,141 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
+	"path/filepath"
 	"slices"
 	"strings"
 
+	"github.com/charmbracelet/huh"
+	"github.com/flowtemplates/flow-cli/internal/builtin"
 	"github.com/flowtemplates/flow-cli/internal/config"
+	"github.com/flowtemplates/flow-cli/internal/hooks"
 	"github.com/flowtemplates/flow-cli/internal/lsp"
+	"github.com/flowtemplates/flow-cli/internal/repository/archive"
+	"github.com/flowtemplates/flow-cli/internal/repository/git"
 	"github.com/flowtemplates/flow-cli/internal/repository/source"
 	"github.com/flowtemplates/flow-cli/internal/repository/templates"
 	"github.com/flowtemplates/flow-cli/internal/service"
 	"github.com/spf13/cobra"
 )
 
-func createService() (*service.Service, error) {
-	cfg, err := config.GetConfig(defaultConfigName)
+// configOptions are set by global flags of root command
+var configOptions config.Options
+
+func loadConfig() (*config.Loaded, error) {
+	l, err := config.Load(defaultConfigName, configOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
-	tr := templates.New(cfg.TemplatesFolder)
+	if err := l.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return l, nil
+}
+
+func createService() (*service.Service, error) {
+	l, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return newService(l.Config)
+}
+
+func newService(cfg *config.Config) (*service.Service, error) {
+	sources, err := templateSources(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := templates.NewMulti(sources...)
 	sr := source.New()
+	hr := hooks.New()
 
-	return service.New(tr, sr), nil
+	return service.New(tr, sr, hr), nil
+}
+
+const (
+	projectSourceName = "project"
+	userSourceName    = "user"
+	builtinSourceName = "builtin"
+)
+
+// templateSources returns project templates folder, configured sources,
+// user-level templates folder and built-in templates in order of precedence
+func templateSources(cfg *config.Config) ([]templates.Source, error) {
+	sources := []templates.Source{{
+		Name: projectSourceName,
+		Repo: templates.New(cfg.TemplatesFolder),
+	}}
+
+	for _, s := range cfg.TemplateSources {
+		if s.Archive != "" {
+			repo, err := archive.New(s.Archive)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template source: %w", err)
+			}
+
+			name := s.Name
+			if name == "" {
+				name = archive.TrimExt(s.Archive)
+			}
+
+			sources = append(sources, templates.Source{
+				Name: name,
+				Repo: repo,
+			})
+			continue
+		}
+
+		if s.Git == "" {
+			name := s.Name
+			if name == "" {
+				name = filepath.Base(s.Path)
+			}
+
+			sources = append(sources, templates.Source{
+				Name: name,
+				Repo: templates.New(s.Path),
+			})
+			continue
+		}
+
+		cacheRoot, err := git.DefaultCacheRoot()
+		if err != nil {
+			return nil, fmt.Errorf("template source %s: %w", s.Git, err)
+		}
+
+		name := s.Name
+		if name == "" {
+			name = strings.TrimSuffix(path.Base(s.Git), ".git")
+		}
+
+		sources = append(sources, templates.Source{
+			Name: name,
+			Repo: git.New(s.Git, s.Ref, s.Path, cacheRoot),
+		})
+	}
+
+	if userDir, err := config.UserTemplatesFolder(); err == nil && fileExists(userDir) {
+		sources = append(sources, templates.Source{
+			Name: userSourceName,
+			Repo: templates.New(userDir),
+		})
+	}
+
+	sources = append(sources, templates.Source{
+		Name: builtinSourceName,
+		Repo: builtin.New(),
+	})
+
+	return sources, nil
 }
 
 type configExt string
@@ -63,43 +174,108 @@ func (e *configExt) Type() string {
 
 func newInitCmd() *cobra.Command {
 	ext := configYml
+	var (
+		dir     string
+		force   bool
+		example bool
+	)
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Creates a new config file in project directory",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println(ext.String())
+			configPath := filepath.Join(dir, defaultConfigName+"."+ext.String())
+
+			existing, ok := config.LookupConfig(dir, defaultConfigName)
+			if !ok && fileExists(configPath) {
+				existing, ok = configPath, true
+			}
+			if ok && !force {
+				return fmt.Errorf("config file %s already exists, use --force to overwrite", existing)
+			}
+
+			if ok && existing != configPath {
+				if err := os.Remove(existing); err != nil {
+					return fmt.Errorf("failed to remove existing config: %w", err)
+				}
+			}
+
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("failed to create project directory: %w", err)
+			}
+
+			cfg := config.Default()
+			if err := config.WriteConfigFile(configPath, cfg); err != nil {
+				return fmt.Errorf("failed to write config: %w", err)
+			}
+
+			templatesDir := filepath.Join(dir, cfg.TemplatesFolder)
+			if err := os.MkdirAll(templatesDir, 0o755); err != nil {
+				return fmt.Errorf("failed to create templates folder: %w", err)
+			}
+
+			if example {
+				if err := writeExampleTemplate(templatesDir); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("Created %s\n", configPath)
+
 			return nil
 		},
 	}
 
-	cmd.Flags().BoolP("path", "p", false, "Path to directory")
+	cmd.Flags().StringVarP(&dir, "path", "p", ".", "Path to directory")
 	cmd.Flags().VarP(&ext, "ext", "e", "Config file extension")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing config file")
+	cmd.Flags().BoolVar(&example, "example", false, "Add an example template to templates folder")
 
 	return cmd
 }
 
 func newListCmd() *cobra.Command {
-	var printJson bool
+	var (
+		printJson bool
+		long      bool
+		tags      []string
+	)
 	cmd := &cobra.Command{
-		Use:   "list",
-		Short: "List all template names",
+		Use:   "list [query]",
+		Short: "List templates, optionally only ones whose name, description or tags match query",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			var query string
+			if len(args) > 0 {
+				query = args[0]
+			}
+
 			s, err := createService()
 			if err != nil {
 				return err
 			}
 
-			templates, err := s.ListTemplates()
+			templates, err := listTemplates(s)
 			if err != nil {
 				return fmt.Errorf("failed to list templates: %w", err)
 			}
+			templates = filterTemplates(visibleTemplates(templates), query, tags)
 
-			if printJson {
+			switch {
+			case printJson:
 				data, _ := json.Marshal(templates)
 				fmt.Printf("%s\n", data)
-			} else {
-				for _, c := range templates {
-					fmt.Printf("- %s\n", c)
+			case long:
+				return printTemplatesTable(os.Stdout, templates)
+			default:
+				for _, t := range templates {
+					switch {
+					case t.Error != "":
+						fmt.Printf("- %s (%s): error: %s\n", t.Name, t.Source, t.Error)
+					case t.Description != "":
+						fmt.Printf("- %s (%s): %s\n", t.Name, t.Source, t.Description)
+					default:
+						fmt.Printf("- %s (%s)\n", t.Name, t.Source)
+					}
 				}
 			}
 
@@ -108,20 +284,93 @@ func newListCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&printJson, "print-json", false, "Output in JSON format")
+	cmd.Flags().BoolVarP(&long, "long", "l", false, "Show variables, files and last modification time in a table")
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Only list templates having all given tags")
+	cmd.MarkFlagsMutuallyExclusive("print-json", "long")
 	return cmd
 }
 
 func newCloneCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "clone",
+		Use:   "clone <template name> <path>",
 		Short: "Creates template with <template_name> from directory or file located in path",
+		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("clone")
+			templateName := args[0]
+			path := args[1]
+			values, _ := cmd.Flags().GetStringSlice("replace")
+
+			replacements, err := parseReplacements(values)
+			if err != nil {
+				return err
+			}
+
+			s, err := createService()
+			if err != nil {
+				return err
+			}
+
+			if err := s.Clone(templateName, path, replacements); err != nil {
+				return fmt.Errorf("failed to clone: %w", err)
+			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().StringSliceP("replace", "r", []string{}, "Literals to replace with variables, e.g. Button=name")
+
+	return cmd
+}
+
+func newPackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pack <template name>",
+		Short: "Packs template with everything it extends into .tar.gz or .zip archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templateName := args[0]
+			output, _ := cmd.Flags().GetString("output")
+
+			// Qualified names such as "team:button" are packed as "button"
+			name := templateName[strings.LastIndex(templateName, ":")+1:]
+			if output == "" {
+				output = name + ".tar.gz"
+			}
+
+			format, err := archive.FormatOf(output)
+			if err != nil {
+				return fmt.Errorf("invalid output: %w", err)
+			}
+
+			s, err := createService()
+			if err != nil {
+				return err
+			}
+
+			dir, err := s.Bundle(templateName)
+			if err != nil {
+				return fmt.Errorf("failed to get template: %w", err)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create archive: %w", err)
+			}
+
+			if err := errors.Join(archive.Write(f, format, name, dir), f.Close()); err != nil {
+				// Incomplete archive is not left behind
+				return errors.Join(fmt.Errorf("failed to pack: %w", err), os.Remove(output))
+			}
+
+			fmt.Println(output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Archive path, format is chosen by extension (default <template name>.tar.gz)")
+
 	return cmd
 }
 
@@ -156,9 +405,16 @@ func newLspProxyCmd() *cobra.Command {
 }
 
 func newCreateCmd() *cobra.Command {
+	var (
+		printJson    bool
+		dryRun       bool
+		force        bool
+		skipExisting bool
+		noHooks      bool
+	)
 	cmd := &cobra.Command{
-		Use:     "create <template name> [...paths]",
-		Short:   "Create selected template to output dirs",
+		Use:     "create <template name> [...paths|@target]",
+		Short:   "Create selected template to output dirs, named targets or default targets of the template",
 		Args:    cobra.MinimumNArgs(1),
 		Aliases: []string{"c"},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -167,26 +423,46 @@ func newCreateCmd() *cobra.Command {
 			values, _ := cmd.Flags().GetStringSlice("values")
 			vars := parseVars(values)
 
-			overWriteFn := func(p []string) ([]string, error) {
-				fmt.Printf("request to overwrite: %v\n", p)
-				return []string{}, nil
+			l, err := loadConfig()
+			if err != nil {
+				return err
 			}
 
-			s, err := createService()
+			paths, err = l.Config.ResolveOutputs(templateName, paths)
+			if err != nil {
+				return fmt.Errorf("failed to resolve outputs: %w", err)
+			}
+
+			s, err := newService(l.Config)
 			if err != nil {
 				return err
 			}
 
-			if err := s.Create(templateName, vars, overWriteFn, paths...); err != nil {
+			plan, err := s.Create(templateName, vars, confirmOverwrites, service.CreateOptions{
+				DryRun:     dryRun,
+				Overwrite:  overwritePolicy(force, skipExisting),
+				NoHooks:    noHooks,
+				HookOutput: os.Stderr,
+			}, paths...)
+			if err != nil {
 				return fmt.Errorf("failed to add: %w", err)
 			}
 
+			if dryRun || printJson {
+				printPlan(os.Stdout, plan, printJson)
+			}
+
 			return nil
 		},
 	}
 
 	cmd.Flags().StringSliceP("values", "v", []string{}, "Values to pass to context")
-	cmd.Flags().Bool("print-json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&printJson, "print-json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print files that would be created without writing them")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing files without asking")
+	cmd.Flags().BoolVar(&skipExisting, "skip-existing", false, "Keep existing files without asking")
+	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Do not run hooks declared by template")
+	cmd.MarkFlagsMutuallyExclusive("force", "skip-existing")
 
 	return cmd
 }
@@ -207,7 +483,16 @@ func newVersionCmd() *cobra.Command {
 	return cmd
 }
 
+type removeResult struct {
+	Template string `json:"template"`
+	Removed  bool   `json:"removed"`
+}
+
 func newRemoveCmd() *cobra.Command {
+	var (
+		printJson bool
+		yes       bool
+	)
 	cmd := &cobra.Command{
 		Use:     "remove <template name>",
 		Short:   "Remove template by name",
@@ -216,17 +501,52 @@ func newRemoveCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			templateName := args[0]
 
-			// err := c.service.Remove(templateName)
-			// if err != nil {
-			// 	return fmt.Errorf("failed to add: %w", err)
-			// }
-			fmt.Println(templateName)
+			s, err := createService()
+			if err != nil {
+				return err
+			}
+
+			exists, err := s.TemplateExists(templateName)
+			if err != nil {
+				return fmt.Errorf("failed to remove: %w", err)
+			}
+
+			if !exists {
+				return fmt.Errorf("failed to remove: %w: %s", service.ErrTemplateNotFound, templateName)
+			}
+
+			res := removeResult{Template: templateName}
+
+			confirmed := yes
+			if !confirmed {
+				if err := huh.NewConfirm().
+					Title(fmt.Sprintf("Remove template %s?", templateName)).
+					Value(&confirmed).
+					Run(); err != nil {
+					return fmt.Errorf("failed to run confirm form: %w", err)
+				}
+			}
+
+			if confirmed {
+				if err := s.Remove(templateName); err != nil {
+					return fmt.Errorf("failed to remove: %w", err)
+				}
+				res.Removed = true
+			}
+
+			if printJson {
+				data, _ := json.Marshal(res)
+				fmt.Printf("%s\n", data)
+			} else if res.Removed {
+				fmt.Printf("Removed %s\n", templateName)
+			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().Bool("print-json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&printJson, "print-json", false, "Output in JSON format")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove without confirmation")
 
 	return cmd
 }
@@ -272,3 +592,50 @@ func newContextCmd() *cobra.Command {
 	// cmd.Flags().Bool("json", false, "Output in JSON format")
 	return cmd
 }
+
+func newTemplatesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "templates",
+		Short: "Manage template sources",
+	}
+
+	cmd.AddCommand(newTemplatesUpdateCmd())
+
+	return cmd
+}
+
+func newTemplatesUpdateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Fetch git template sources and check out their configured refs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			l, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			sources, err := templateSources(l.Config)
+			if err != nil {
+				return err
+			}
+
+			for _, s := range sources {
+				gr, ok := s.Repo.(*git.GitRepo)
+				if !ok {
+					continue
+				}
+
+				commit, err := gr.Update()
+				if err != nil {
+					return fmt.Errorf("failed to update %s: %w", s.Name, err)
+				}
+
+				fmt.Printf("%s: %s\n", s.Name, commit)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+	}
+
+	cmd.AddCommand(newConfigShowCmd())
+	cmd.AddCommand(newConfigSchemaCmd())
+
+	return cmd
+}
+
+type configShowResult struct {
+	Config  *config.Config           `json:"config"`
+	Origins map[string]config.Origin `json:"origins"`
+	Files   []string                 `json:"files"`
+}
+
+func newConfigShowCmd() *cobra.Command {
+	var printJson bool
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print effective configuration and the layer each value came from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Invalid values are still shown, problems are reported after them
+			l, err := config.Load(defaultConfigName, configOptions)
+			if err != nil {
+				return fmt.Errorf("failed to get config: %w", err)
+			}
+			defer func() {
+				if err := l.Validate(); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+				}
+			}()
+
+			if printJson {
+				data, _ := json.Marshal(configShowResult{
+					Config:  l.Config,
+					Origins: l.Origins,
+					Files:   l.Files,
+				})
+				fmt.Printf("%s\n", data)
+				return nil
+			}
+
+			return printConfig(os.Stdout, l)
+		},
+	}
+
+	cmd.Flags().BoolVar(&printJson, "print-json", false, "Output in JSON format")
+	return cmd
+}
+
+// printConfig prints effective values with their origins aligned in columns
+func printConfig(w io.Writer, l *config.Loaded) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "templatesFolder\t%s\t(%s)\n", l.Config.TemplatesFolder, l.Origins["templatesFolder"])
+
+	for i, s := range l.Config.TemplateSources {
+		key := fmt.Sprintf("templateSources[%d]", i)
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", key, describeSource(s), l.Origins[key])
+	}
+
+	for _, name := range l.Config.TargetNames() {
+		key := "targets." + name
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", key, l.Config.Targets[name], l.Origins[key])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(l.Config.DefaultTargets)) {
+		key := "defaultTargets." + name
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", key, strings.Join(l.Config.DefaultTargets[name], ", "), l.Origins[key])
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to print config: %w", err)
+	}
+
+	return nil
+}
+
+func describeSource(s config.TemplateSource) string {
+	var location string
+	switch {
+	case s.Archive != "":
+		location = "archive " + s.Archive
+	case s.Git != "":
+		location = "git " + s.Git
+		if s.Ref != "" {
+			location += "@" + s.Ref
+		}
+		if s.Path != "" {
+			location += " " + s.Path
+		}
+	default:
+		location = s.Path
+	}
+
+	if s.Name == "" {
+		return location
+	}
+
+	return fmt.Sprintf("%s: %s", s.Name, location)
+}
+
+func newConfigSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print JSON Schema of flow config for editor integration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := os.Stdout.Write(config.Schema); err != nil {
+				return fmt.Errorf("failed to write schema: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -1,7 +1,17 @@
 package main
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"github.com/flowtemplates/flow-cli/internal/service"
 )
 
 func parseVars(vars []string) map[string]*string {
@@ -17,3 +27,170 @@ func parseVars(vars []string) map[string]*string {
 
 	return res
 }
+
+func parseReplacements(values []string) (map[string]string, error) {
+	res := make(map[string]string)
+	for _, v := range values {
+		literal, variable, ok := strings.Cut(v, "=")
+		if !ok || literal == "" || variable == "" {
+			return nil, fmt.Errorf("invalid replacement %q, expected <literal>=<variable>", v)
+		}
+
+		res[literal] = variable
+	}
+
+	return res, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// listTemplates lists templates of every readable source,
+// warning about each source whose templates cannot be listed
+func listTemplates(s *service.Service) ([]service.TemplateInfo, error) {
+	templates, err := s.ListTemplates()
+	if err != nil && templates == nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	if err != nil {
+		errs := []error{err}
+		var joined interface{ Unwrap() []error }
+		if errors.As(err, &joined) {
+			errs = joined.Unwrap()
+		}
+
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+		}
+	}
+
+	return templates, nil
+}
+
+// visibleTemplates hides built-in templates once any other template exists.
+// They are still available by qualified name, e.g. "builtin:readme"
+func visibleTemplates(ts []service.TemplateInfo) []service.TemplateInfo {
+	custom := slices.DeleteFunc(slices.Clone(ts), func(t service.TemplateInfo) bool {
+		return t.Source == builtinSourceName
+	})
+	if len(custom) == 0 {
+		return ts
+	}
+
+	return custom
+}
+
+// filterTemplates keeps templates whose name, description or tags contain query
+// and which have every tag of tags. Matching is case-insensitive
+func filterTemplates(ts []service.TemplateInfo, query string, tags []string) []service.TemplateInfo {
+	query = strings.ToLower(query)
+
+	return slices.DeleteFunc(slices.Clone(ts), func(t service.TemplateInfo) bool {
+		for _, tag := range tags {
+			if !slices.ContainsFunc(t.Tags, func(s string) bool {
+				return strings.EqualFold(s, tag)
+			}) {
+				return true
+			}
+		}
+
+		if query == "" {
+			return false
+		}
+
+		fields := append([]string{t.Name, t.Description}, t.Tags...)
+		return !slices.ContainsFunc(fields, func(s string) bool {
+			return strings.Contains(strings.ToLower(s), query)
+		})
+	})
+}
+
+// templateGroup returns group template is shown in by picker:
+// its first tag or, for untagged templates, the source it comes from
+func templateGroup(t service.TemplateInfo) string {
+	if len(t.Tags) > 0 {
+		return t.Tags[0]
+	}
+
+	return t.Source
+}
+
+// templateOptions returns picker options sorted by group and name
+func templateOptions(ts []service.TemplateInfo) []huh.Option[string] {
+	ts = slices.Clone(ts)
+	slices.SortStableFunc(ts, func(a, b service.TemplateInfo) int {
+		return cmp.Or(
+			cmp.Compare(templateGroup(a), templateGroup(b)),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+
+	options := make([]huh.Option[string], 0, len(ts))
+	for _, t := range ts {
+		label := fmt.Sprintf("%s / %s", templateGroup(t), t.Name)
+		if len(t.Tags) > 0 {
+			label = fmt.Sprintf("%s (%s)", label, t.Source)
+		}
+		if t.Description != "" {
+			label = fmt.Sprintf("%s - %s", label, t.Description)
+		}
+		options = append(options, huh.NewOption(label, t.Name))
+	}
+
+	return options
+}
+
+const exampleTemplateName = "example"
+
+var exampleTemplateFiles = map[string]string{
+	"{{name}}/README.md.ft": "# {{name}}\n",
+}
+
+func writeExampleTemplate(templatesDir string) error {
+	for path, source := range exampleTemplateFiles {
+		fullPath := filepath.Join(templatesDir, exampleTemplateName, path)
+		if fileExists(fullPath) {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return fmt.Errorf("failed to create example template: %w", err)
+		}
+
+		if err := os.WriteFile(fullPath, []byte(source), 0o644); err != nil {
+			return fmt.Errorf("failed to write example template: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type destinationChoice struct {
+	label   string
+	outputs []string
+}
+
+// destinationChoices returns destinations offered by picker: default targets
+// of template, every named target and, the last one, a folder picked from disk
+func destinationChoices(cfg *config.Config, templateName string) []destinationChoice {
+	var choices []destinationChoice
+
+	if defaults := cfg.DefaultOutputs(templateName); len(defaults) > 0 {
+		choices = append(choices, destinationChoice{
+			label:   fmt.Sprintf("%s (default)", strings.Join(defaults, ", ")),
+			outputs: defaults,
+		})
+	}
+
+	for _, name := range cfg.TargetNames() {
+		choices = append(choices, destinationChoice{
+			label:   fmt.Sprintf("%s%s (%s)", config.TargetPrefix, name, cfg.Targets[name]),
+			outputs: []string{config.TargetPrefix + name},
+		})
+	}
+
+	return append(choices, destinationChoice{label: "Other folder..."})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/huh"
+	"github.com/flowtemplates/flow-cli/internal/diff"
+	"github.com/flowtemplates/flow-cli/internal/service"
+)
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// overwritePolicy returns policy of --force and --skip-existing flags.
+// Without them user is asked about every file
+func overwritePolicy(force bool, skipExisting bool) service.OverwritePolicy {
+	switch {
+	case force:
+		return service.OverwriteAll
+	case skipExisting:
+		return service.OverwriteNone
+	default:
+		return service.OverwriteAsk
+	}
+}
+
+// confirmOverwrites prints diff of every file and asks whether it should be overwritten.
+// Diffs go to stderr, so they do not mix with the output printed as JSON
+func confirmOverwrites(files []service.Overwrite) ([]string, error) {
+	color := isTerminal(os.Stderr)
+	paths := []string{}
+
+	for _, f := range files {
+		d := diff.Unified(f.Path, f.Path, f.Existing, f.Rendered)
+		switch {
+		case f.LinkTarget != "":
+			d = fmt.Sprintf("%s will be replaced with a link to %s\n", f.Path, f.LinkTarget)
+		case f.Binary:
+			d = fmt.Sprintf("Binary file %s differs\n", f.Path)
+		case d == "":
+			fmt.Fprintf(os.Stderr, "%s is up to date\n", f.Path)
+			continue
+		}
+
+		if color {
+			d = diff.Colorize(d)
+		}
+		fmt.Fprint(os.Stderr, d)
+
+		var overwrite bool
+		if err := huh.NewConfirm().
+			Title(fmt.Sprintf("Overwrite %s?", f.Path)).
+			Affirmative("Overwrite").
+			Negative("Skip").
+			Value(&overwrite).
+			Run(); err != nil {
+			return nil, fmt.Errorf("failed to run overwrite form: %w", err)
+		}
+
+		if overwrite {
+			paths = append(paths, f.Path)
+		}
+	}
+
+	return paths, nil
+}
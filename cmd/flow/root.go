@@ -2,22 +2,45 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
 
 	"github.com/charmbracelet/huh"
+	"github.com/flowtemplates/flow-cli/internal/service"
 	"github.com/flowtemplates/flow-go/types"
 	"github.com/spf13/cobra"
 )
 
 func cmd() *cobra.Command {
+	var (
+		printJson bool
+		dryRun    bool
+		noHooks   bool
+	)
 	rootCmd := &cobra.Command{
 		Use:   "flow",
 		Short: "Flow CLI",
 		Long:  "Modern toolchain for component code generation.",
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return handleMain()
+			return handleMain(dryRun, printJson, noHooks)
 		},
 	}
 
+	rootCmd.Flags().BoolVar(&printJson, "print-json", false, "Output in JSON format")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print files that would be created without writing them")
+	rootCmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Do not run hooks declared by template")
+
+	rootCmd.PersistentFlags().StringVar(
+		&configOptions.ConfigFile, "config", "",
+		"Project config file, found in working directory or its parents by default",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&configOptions.TemplatesDir, "templates-dir", "",
+		"Templates folder, overrides config",
+	)
+
 	rootCmd.AddCommand(newListCmd())
 	rootCmd.AddCommand(newContextCmd())
 	rootCmd.AddCommand(newCreateCmd())
@@ -25,22 +48,33 @@ func cmd() *cobra.Command {
 	rootCmd.AddCommand(newUpgradeCmd())
 	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newCloneCmd())
+	rootCmd.AddCommand(newPackCmd())
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newLspProxyCmd())
+	rootCmd.AddCommand(newTemplatesCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newWorkspacesCmd())
 
 	return rootCmd
 }
 
-func handleMain() error {
-	s, err := createService()
+func handleMain(dryRun bool, printJson bool, noHooks bool) error {
+	l, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg := l.Config
+
+	s, err := newService(cfg)
 	if err != nil {
 		return err
 	}
 
-	templates, err := s.ListTemplates()
+	templates, err := listTemplates(s)
 	if err != nil {
 		return fmt.Errorf("failed to load templates: %w", err)
 	}
+	templates = visibleTemplates(templates)
 
 	var templateName string
 
@@ -48,13 +82,10 @@ func handleMain() error {
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title("Select a template").
-				OptionsFunc(func() []huh.Option[string] {
-					var options []huh.Option[string]
-					for _, t := range templates {
-						options = append(options, huh.NewOption(t, t))
-					}
-					return options
-				}, &templateName).
+				Description("Type to filter by group, name or description").
+				Options(templateOptions(templates)...).
+				Filtering(true).
+				Height(15).
 				Value(&templateName),
 		),
 	)
@@ -63,7 +94,7 @@ func handleMain() error {
 		return fmt.Errorf("failed to run template form: %w", err)
 	}
 
-	tm, err := s.GetTemplateContext(templateName)
+	tc, err := s.GetTemplateContext(templateName)
 	if err != nil {
 		return fmt.Errorf("failed to get template: %w", err)
 	}
@@ -73,23 +104,64 @@ func handleMain() error {
 	var formFields []huh.Field
 	var flagFields []huh.Option[string]
 
-	for name, typ := range tm {
-		if typ == types.Boolean {
-			flagFields = append(flagFields, huh.NewOption(name, name))
+	names := make([]string, 0, len(tc))
+	for name := range tc {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		v := tc[name]
+		if v.Type == types.Boolean {
+			title := name
+			if v.Description != "" {
+				title = fmt.Sprintf("%s - %s", name, v.Description)
+			}
+
+			selected := v.Default != nil && *v.Default == "true"
+			flagFields = append(flagFields, huh.NewOption(title, name).Selected(selected))
 		} else {
 			var input string
-			formFields = append(formFields, huh.NewInput().
+			if v.Default != nil {
+				input = *v.Default
+			}
+			variableMap[name] = &input
+
+			if len(v.Enum) > 0 {
+				formFields = append(formFields, huh.NewSelect[string]().
+					Title(name).
+					Description(v.Description).
+					Key(name).
+					Options(huh.NewOptions(v.Enum...)...).
+					Value(&input),
+				)
+				continue
+			}
+
+			field := huh.NewInput().
 				Title(name).
+				Description(v.Description).
 				Key(name).
-				Value(&input),
-			)
+				Value(&input)
 
-			variableMap[name] = &input
+			if v.Pattern != "" {
+				pattern := regexp.MustCompile(v.Pattern)
+				field = field.Validate(func(s string) error {
+					if !pattern.MatchString(s) {
+						return fmt.Errorf("must match %s", v.Pattern)
+					}
+					return nil
+				})
+			}
+
+			formFields = append(formFields, field)
 		}
 	}
 
 	var selectedFlags []string
 	var dest string
+	choices := destinationChoices(cfg, templateName)
+	choice := 0
 
 	groups := []*huh.Group{}
 	if len(formFields) > 0 {
@@ -105,6 +177,22 @@ func handleMain() error {
 		))
 	}
 
+	// Folder is picked from disk only if no configured destination is chosen
+	otherFolder := len(choices) - 1
+	if len(choices) > 1 {
+		options := make([]huh.Option[int], len(choices))
+		for i, c := range choices {
+			options[i] = huh.NewOption(c.label, i)
+		}
+
+		groups = append(groups, huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Select destination").
+				Options(options...).
+				Value(&choice),
+		))
+	}
+
 	groups = append(groups,
 		huh.NewGroup(
 			huh.NewFilePicker().
@@ -114,7 +202,9 @@ func handleMain() error {
 				Picking(true).
 				ShowPermissions(false).
 				Value(&dest),
-		))
+		).WithHideFunc(func() bool {
+			return choice != otherFolder
+		}))
 
 	paramsForm := huh.NewForm(groups...)
 
@@ -122,36 +212,33 @@ func handleMain() error {
 		return fmt.Errorf("failed to run form: %w", err)
 	}
 
-	for _, name := range selectedFlags {
-		variableMap[name] = nil
-	}
-
-	overWriteFn := func(paths []string) ([]string, error) {
-		ov := []string{}
-		overwriteForm := huh.NewForm(
-			huh.NewGroup(
-				huh.NewMultiSelect[string]().
-					Title("Select files to overwrite").
-					OptionsFunc(func() []huh.Option[string] {
-						var options []huh.Option[string]
-						for _, t := range paths {
-							options = append(options, huh.NewOption(t, t).Selected(true))
-						}
-						return options
-					}, &templateName).
-					Value(&ov),
-			),
-		)
-		if err := overwriteForm.Run(); err != nil {
-			return nil, fmt.Errorf("failed to run overwrite form: %w", err)
+	for _, name := range names {
+		if tc[name].Type == types.Boolean {
+			flag := strconv.FormatBool(slices.Contains(selectedFlags, name))
+			variableMap[name] = &flag
 		}
+	}
 
-		return ov, nil
+	outputs := []string{dest}
+	if choice != otherFolder {
+		outputs, err = cfg.ResolveOutputs(templateName, choices[choice].outputs)
+		if err != nil {
+			return err
+		}
 	}
 
-	if err := s.Create(templateName, variableMap, overWriteFn, dest); err != nil {
+	plan, err := s.Create(templateName, variableMap, confirmOverwrites, service.CreateOptions{
+		DryRun:     dryRun,
+		NoHooks:    noHooks,
+		HookOutput: os.Stderr,
+	}, outputs...)
+	if err != nil {
 		return fmt.Errorf("failed to add: %w", err)
 	}
 
+	if dryRun || printJson {
+		printPlan(os.Stdout, plan, printJson)
+	}
+
 	return nil
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/flowtemplates/flow-cli/internal/service"
+)
+
+const modTimeLayout = "2006-01-02 15:04"
+
+// printTemplatesTable prints templates with their metadata aligned in columns
+func printTemplatesTable(w io.Writer, templates []service.TemplateInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tVARIABLES\tFILES\tMODIFIED\tDESCRIPTION")
+
+	for _, t := range templates {
+		if t.Error != "" {
+			// Multiline errors would break the columns
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\terror: %s\n", t.Name, t.Source, strings.Join(strings.Fields(t.Error), " "))
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Name,
+			t.Source,
+			strconv.Itoa(t.Variables),
+			strconv.Itoa(t.Files),
+			formatModTime(t.ModTime),
+			t.Description,
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to print templates: %w", err)
+	}
+
+	return nil
+}
+
+func formatModTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Local().Format(modTimeLayout)
+}
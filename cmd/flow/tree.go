@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/service"
+)
+
+type treeNode struct {
+	name     string
+	action   service.FileAction
+	children []*treeNode
+}
+
+func (n *treeNode) child(name string) *treeNode {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+
+	c := &treeNode{name: name}
+	n.children = append(n.children, c)
+
+	return c
+}
+
+func (n *treeNode) label() string {
+	if n.action == "" {
+		return n.name
+	}
+
+	return fmt.Sprintf("%s (%s)", n.name, n.action)
+}
+
+func printTreeNodes(w io.Writer, nodes []*treeNode, prefix string) {
+	for i, n := range nodes {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+
+		fmt.Fprintf(w, "%s%s%s\n", prefix, connector, n.label())
+		printTreeNodes(w, n.children, prefix+childPrefix)
+	}
+}
+
+// printPlan prints planned files as a file tree or as JSON
+func printPlan(w io.Writer, plan []service.PlannedFile, printJson bool) {
+	if printJson {
+		data, _ := json.Marshal(plan)
+		fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+
+	root := &treeNode{}
+	for _, f := range plan {
+		n := root
+		path := filepath.ToSlash(f.Path)
+		if strings.HasPrefix(path, "/") {
+			n = n.child("/")
+		}
+
+		for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+			n = n.child(part)
+		}
+		n.action = f.Action
+	}
+
+	for _, n := range root.children {
+		fmt.Fprintln(w, n.label())
+		printTreeNodes(w, n.children, "")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"github.com/flowtemplates/flow-cli/internal/service"
+	"github.com/spf13/cobra"
+)
+
+type workspace struct {
+	// Dir is relative to workspace root
+	Dir       string                 `json:"dir"`
+	Config    string                 `json:"config"`
+	Templates []service.TemplateInfo `json:"templates"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// loadWorkspace lists templates visible from config file. Templates of readable
+// sources are returned along with error of the sources that cannot be listed
+func loadWorkspace(configFile string) ([]service.TemplateInfo, error) {
+	l, err := config.Load(defaultConfigName, config.Options{ConfigFile: configFile})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	s, err := newService(l.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := s.ListTemplates()
+
+	return visibleTemplates(templates), err
+}
+
+func newWorkspacesCmd() *cobra.Command {
+	var printJson bool
+	cmd := &cobra.Command{
+		Use:   "workspaces",
+		Short: "List every config of the workspace and templates visible from each",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			l, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			root := l.WorkspaceRoot
+			if root == "" {
+				return fmt.Errorf("no project config found")
+			}
+
+			configs, err := config.WorkspaceConfigs(root, defaultConfigName)
+			if err != nil {
+				return err
+			}
+
+			workspaces := make([]workspace, 0, len(configs))
+			for _, configFile := range configs {
+				dir, _ := filepath.Rel(root, filepath.Dir(configFile))
+				w := workspace{
+					Dir:    dir,
+					Config: configFile,
+				}
+
+				// Broken workspace should not hide the others
+				w.Templates, err = loadWorkspace(configFile)
+				if err != nil {
+					w.Error = err.Error()
+				}
+
+				workspaces = append(workspaces, w)
+			}
+
+			if printJson {
+				data, _ := json.Marshal(workspaces)
+				fmt.Printf("%s\n", data)
+				return nil
+			}
+
+			for _, w := range workspaces {
+				fmt.Printf("%s (%s)\n", w.Dir, w.Config)
+				if w.Error != "" {
+					fmt.Printf("  error: %s\n", strings.ReplaceAll(w.Error, "\n", "\n  error: "))
+				}
+
+				for _, t := range w.Templates {
+					fmt.Printf("  - %s (%s)\n", t.Name, t.Source)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printJson, "print-json", false, "Output in JSON format")
+	return cmd
+}
@@ -0,0 +1,23 @@
+// Package builtin holds default templates compiled into flow binary
+package builtin
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+)
+
+//go:embed all:templates
+var templatesFS embed.FS
+
+// New returns read-only repo of built-in templates
+func New() *templates.FSRepo {
+	sub, err := fs.Sub(templatesFS, "templates")
+	if err != nil {
+		// templates dir is embedded at compile time
+		panic(err)
+	}
+
+	return templates.NewFS(sub)
+}
@@ -0,0 +1,30 @@
+package builtin_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/builtin"
+	"gotest.tools/v3/assert"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	t.Parallel()
+
+	r := builtin.New()
+
+	names, err := r.GetTemplatesNames()
+	assert.NilError(t, err)
+	assert.Assert(t, len(names) > 0)
+
+	for _, name := range names {
+		dir, m, err := r.GetTemplate(name)
+		assert.NilError(t, err, name)
+		assert.Assert(t, m.Description != "", name)
+		assert.Assert(t, len(dir.Files) > 0, name)
+
+		for _, f := range dir.Files {
+			assert.Equal(t, f.Mode&0o200, os.FileMode(0o200), "%s/%s must be writable", name, f.Path)
+		}
+	}
+}
@@ -9,9 +9,43 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultTemplatesFolder is the templates folder written by newly initialized configs
+const DefaultTemplatesFolder = ".flow"
+
+// TemplateSource is an additional folder, git repository or archive with templates
+type TemplateSource struct {
+	// Name is used to qualify templates of the source, e.g. "team:button"
+	Name string `json:"name" yaml:"name"`
+	// Path is the templates folder, or its path inside repository for git sources
+	Path string `json:"path" yaml:"path"`
+	// Git is the url of repository containing templates
+	Git string `json:"git,omitempty" yaml:"git,omitempty"`
+	// Ref is a branch, tag or commit of Git repository, HEAD by default
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// Archive is a .tar.gz or .zip template pack, local path or file:// url
+	Archive string `json:"archive,omitempty" yaml:"archive,omitempty"`
+}
+
 // Config struct defining expected fields
 type Config struct {
 	TemplatesFolder string `json:"templatesFolder" yaml:"templatesFolder"`
+	// TemplateSources are searched after TemplatesFolder in the listed order
+	TemplateSources []TemplateSource `json:"templateSources,omitempty" yaml:"templateSources,omitempty"`
+	// Targets are named output directories, used as @name in place of output paths
+	Targets map[string]string `json:"targets,omitempty" yaml:"targets,omitempty"`
+	// DefaultTargets are outputs, @targets or paths, used by template when none are given
+	DefaultTargets map[string][]string `json:"defaultTargets,omitempty" yaml:"defaultTargets,omitempty"`
+	// Root marks workspace root config. Configs of nested directories inherit it
+	Root bool `json:"root,omitempty" yaml:"root,omitempty"`
+	// Extends is a config file, or a directory containing one, whose values are inherited
+	Extends string `json:"extends,omitempty" yaml:"extends,omitempty"`
+}
+
+// Default returns config used for newly initialized projects
+func Default() *Config {
+	return &Config{
+		TemplatesFolder: DefaultTemplatesFolder,
+	}
 }
 
 // fileExists checks if a file exists
@@ -20,6 +54,20 @@ func fileExists(filename string) bool {
 	return err == nil
 }
 
+var extensions = []string{".json", ".jsonc", ".yaml", ".yml"}
+
+// LookupConfig returns path of the config file located directly in dir
+func LookupConfig(dir string, baseName string) (string, bool) {
+	for _, ext := range extensions {
+		configPath := filepath.Join(dir, baseName+ext)
+		if fileExists(configPath) {
+			return configPath, true
+		}
+	}
+
+	return "", false
+}
+
 // findConfig searches for the config file in the current directory and its parents
 func findConfig(baseName string) (string, error) {
 	dir, err := os.Getwd()
@@ -27,27 +75,15 @@ func findConfig(baseName string) (string, error) {
 		return "", fmt.Errorf("failed to get pwd: %w", err)
 	}
 
-	extensions := []string{".json", ".yaml", ".yml"}
-
-	for {
-		for _, ext := range extensions {
-			configPath := filepath.Join(dir, baseName+ext)
-			if fileExists(configPath) {
-				return configPath, nil
-			}
-		}
-
-		parentDir := filepath.Dir(dir)
-		if parentDir == dir {
-			break
-		}
-		dir = parentDir
+	if configPath, ok := findConfigFrom(dir, baseName); ok {
+		return configPath, nil
 	}
 
-	return "", fmt.Errorf("config file %s[.json/.yaml/.yml] not found", baseName)
+	return "", fmt.Errorf("config file %s[.json/.jsonc/.yaml/.yml] not found", baseName)
 }
 
-// ReadConfigFile reads and parses a JSON or YAML file into the provided struct
+// ReadConfigFile reads and parses a JSON, JSONC or YAML file into the provided struct.
+// Unknown keys are rejected, errors are reported as file:line[:column]
 func ReadConfigFile(filename string, v *Config) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -57,31 +93,68 @@ func ReadConfigFile(filename string, v *Config) error {
 	// Detect file type and parse accordingly
 	switch filepath.Ext(filename) {
 	case ".json":
-		err = json.Unmarshal(data, v)
+		err = unmarshalJSON(data, v)
+	case ".jsonc":
+		err = unmarshalJSONC(data, v)
 	case ".yaml", ".yml":
-		err = yaml.Unmarshal(data, v)
+		err = unmarshalYAML(data, v)
 	default:
 		return fmt.Errorf("unsupported file format: %s", filename)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", filename, err)
+		withFile(err, filename)
+		return fmt.Errorf("invalid config: %w", err)
 	}
 
 	return nil
 }
 
-// GetConfig searches for a config file, reads, and parses it into a Config struct
-func GetConfig(baseName string) (*Config, error) {
-	configPath, err := findConfig(baseName)
+// WriteConfigFile serializes config into JSON or YAML file depending on its extension
+func WriteConfigFile(filename string, v *Config) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch filepath.Ext(filename) {
+	case ".json", ".jsonc":
+		data, err = json.MarshalIndent(v, "", "  ")
+		data = append(data, '\n')
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(v)
+	default:
+		return fmt.Errorf("unsupported file format: %s", filename)
+	}
+
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to serialize %s: %w", filename, err)
+	}
+
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// UserTemplatesFolder returns path of user-level templates folder,
+// e.g. ~/.config/flow/templates
+func UserTemplatesFolder() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config dir: %w", err)
 	}
 
-	var config Config
-	if err := ReadConfigFile(configPath, &config); err != nil {
+	return filepath.Join(dir, "flow", "templates"), nil
+}
+
+// GetConfig returns configuration merged from all layers without command line options
+func GetConfig(baseName string) (*Config, error) {
+	l, err := Load(baseName, Options{})
+	if err != nil {
 		return nil, err
 	}
 
-	return &config, nil
+	return l.Config, nil
 }
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PositionError is a config error located at line and column (both 1-based).
+// Column is zero if unknown
+type PositionError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *PositionError) Error() string {
+	pos := strconv.Itoa(e.Line)
+	if e.Column > 0 {
+		pos = fmt.Sprintf("%d:%d", e.Line, e.Column)
+	}
+
+	if e.File == "" {
+		return fmt.Sprintf("%s: %s", pos, e.Err)
+	}
+
+	return fmt.Sprintf("%s:%s: %s", e.File, pos, e.Err)
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// position converts byte offset in data to line and column
+func position(data []byte, offset int64) (int, int) {
+	line, col := 1, 1
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+
+	return line, col
+}
+
+// unmarshalJSON strictly decodes data into v reporting position of errors
+func unmarshalJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		offset    int64
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		// Offset points right after the offending token
+		offset = max(syntaxErr.Offset-1, 0)
+	case errors.As(err, &typeErr):
+		offset = max(typeErr.Offset-1, 0)
+	default:
+		// Decoder does not report position of unknown fields, look for the key instead
+		field, ok := strings.CutPrefix(err.Error(), `json: unknown field "`)
+		if !ok {
+			return err
+		}
+		field = strings.TrimSuffix(field, `"`)
+
+		key := regexp.MustCompile(regexp.QuoteMeta(strconv.Quote(field)) + `\s*:`)
+		loc := key.FindIndex(data)
+		if loc == nil {
+			return err
+		}
+
+		offset = int64(loc[0])
+		err = fmt.Errorf("unknown field %q", field)
+	}
+
+	line, col := position(data, offset)
+
+	return &PositionError{Line: line, Column: col, Err: err}
+}
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError converts message of yaml error to PositionError if it has a line
+func yamlError(msg string) error {
+	m := yamlLineRe.FindStringSubmatch(msg)
+	if m == nil {
+		return errors.New(msg)
+	}
+
+	line, _ := strconv.Atoi(m[1])
+
+	return &PositionError{Line: line, Err: errors.New(m[2])}
+}
+
+// unmarshalYAML strictly decodes data into v reporting lines of errors
+func unmarshalYAML(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err := dec.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return yamlError(err.Error())
+	}
+
+	errs := make([]error, len(typeErr.Errors))
+	for i, msg := range typeErr.Errors {
+		errs[i] = yamlError(msg)
+	}
+
+	return errors.Join(errs...)
+}
+
+// withFile sets file of every PositionError in err
+func withFile(err error, file string) {
+	var posErr *PositionError
+	if errors.As(err, &posErr) {
+		posErr.File = file
+	}
+
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		for _, e := range joined.Unwrap() {
+			withFile(e, file)
+		}
+	}
+}
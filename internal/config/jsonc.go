@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+)
+
+// unmarshalJSONC decodes JSON with comments and trailing commas into v
+func unmarshalJSONC(data []byte, v any) error {
+	plain, err := stripJSONC(data)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalJSON(plain, v)
+}
+
+// stripJSONC replaces comments and trailing commas of data with spaces.
+// Line breaks are kept, so positions in result match the original
+func stripJSONC(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' && out[i] != '\r' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := i
+			for end < len(out) && out[end] != '\n' {
+				end++
+			}
+			blank(i, end)
+			i = end
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := i + 2
+			for end+1 < len(out) && (out[end] != '*' || out[end+1] != '/') {
+				end++
+			}
+			if end+1 >= len(out) {
+				line, col := position(data, int64(i))
+				return nil, &PositionError{Line: line, Column: col, Err: errors.New("unterminated comment")}
+			}
+			blank(i, end+2)
+			i = end + 1
+		}
+	}
+
+	// Comments are gone, so only whitespace can separate trailing comma from closing bracket
+	inString = false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			next := i + 1
+			for next < len(out) && isSpace(out[next]) {
+				next++
+			}
+			if next < len(out) && (out[next] == '}' || out[next] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
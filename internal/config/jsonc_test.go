@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"gotest.tools/v3/assert"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	assert.NilError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestReadJSONC(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "flow.jsonc", `{
+  // templates live next to sources
+  "templatesFolder": "tpl // not a comment", /* inline */
+  "templateSources": [
+    {"name": "team", "path": "../shared",},
+  ],
+}
+`)
+
+	var cfg config.Config
+	assert.NilError(t, config.ReadConfigFile(path, &cfg))
+	assert.Equal(t, cfg.TemplatesFolder, "tpl // not a comment")
+	assert.DeepEqual(t, cfg.TemplateSources, []config.TemplateSource{{Name: "team", Path: "../shared"}})
+}
+
+func TestReadJSONCErrorPosition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+	}{
+		{
+			name:    "syntax",
+			content: "{\n  // comment\n  \"templatesFolder\" \".flow\"\n}\n",
+			line:    3,
+			column:  21,
+		},
+		{
+			name:    "type",
+			content: "{\n  \"templatesFolder\": 1\n}\n",
+			line:    2,
+			column:  22,
+		},
+		{
+			name:    "unterminated comment",
+			content: "{\n  /* comment\n}\n",
+			line:    2,
+			column:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeConfig(t, "flow.jsonc", tt.content)
+
+			var cfg config.Config
+			err := config.ReadConfigFile(path, &cfg)
+
+			var posErr *config.PositionError
+			assert.Assert(t, errors.As(err, &posErr), "unexpected error: %v", err)
+			assert.Equal(t, posErr.Line, tt.line)
+			assert.Equal(t, posErr.Column, tt.column)
+		})
+	}
+}
+
+func TestReadConfigUnknownField(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		file    string
+		content string
+		want    string
+	}{
+		{
+			file:    "flow.json",
+			content: "{\n  \"templateFolder\": \".flow\"\n}\n",
+			want:    `flow.json:2:3: unknown field "templateFolder"`,
+		},
+		{
+			file:    "flow.jsonc",
+			content: "{\n  // comment\n  \"templatesFolder\": \".flow\",\n  \"templateSources\": [{\"paht\": \"x\"}],\n}\n",
+			want:    `flow.jsonc:4:24: unknown field "paht"`,
+		},
+		{
+			file:    "flow.yaml",
+			content: "templatesFolder: .flow\ntemplateFolder: .flow\n",
+			want:    "flow.yaml:2: field templateFolder not found in type config.Config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeConfig(t, tt.file, tt.content)
+
+			var cfg config.Config
+			err := config.ReadConfigFile(path, &cfg)
+			assert.ErrorContains(t, err, filepath.Dir(path)+string(filepath.Separator)+tt.want)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Layer is a configuration channel. Later layers override earlier ones:
+// user file, project file, environment variables, command line flags
+type Layer string
+
+const (
+	LayerUser    Layer = "user"
+	LayerProject Layer = "project"
+	LayerEnv     Layer = "env"
+	LayerFlag    Layer = "flag"
+)
+
+const (
+	// EnvConfig is the path of project config file, overrides search from working directory
+	EnvConfig = "FLOW_CONFIG"
+	// EnvTemplatesFolder overrides templatesFolder
+	EnvTemplatesFolder = "FLOW_TEMPLATES_FOLDER"
+)
+
+// Origin tells where a configuration value came from
+type Origin struct {
+	Layer Layer `json:"layer"`
+	// Source is the config file for file layers, the variable for env layer
+	// and the flag for flag layer
+	Source string `json:"source"`
+}
+
+func (o Origin) String() string {
+	return fmt.Sprintf("%s: %s", o.Layer, o.Source)
+}
+
+// Options are configuration values passed on command line
+type Options struct {
+	// ConfigFile is used instead of searching for project config
+	ConfigFile string
+	// TemplatesDir overrides templatesFolder
+	TemplatesDir string
+}
+
+// Loaded is the effective configuration merged from all layers.
+// Targets and default targets are merged by name
+type Loaded struct {
+	Config *Config
+	// Origins maps keys of effective values, e.g. "templatesFolder" or
+	// "templateSources[0]", to the layer they came from
+	Origins map[string]Origin
+	// Files are the config files read, from lowest precedence to highest
+	Files []string
+	// ProjectFile is the project config file, empty if there is none.
+	// Relative paths of a config file are resolved against its directory
+	ProjectFile string
+	// WorkspaceRoot is the directory of workspace root config project config inherits,
+	// or of project config itself if it is not part of a workspace
+	WorkspaceRoot string
+
+	sourceOrigins []Origin
+	// sourceIndexes are indexes of sources in templateSources of configs they came from,
+	// -1 for sources not listed there
+	sourceIndexes []int
+	// folderTail is the number of sources with lower precedence than templates folder,
+	// those of config setting it and of configs applied before
+	folderTail int
+}
+
+// apply merges values set in cfg over the loaded ones. Template sources of
+// higher layers are searched first, sources of lower layers stay available after them
+func (l *Loaded) apply(cfg *Config, origin Origin) {
+	if cfg.TemplatesFolder != "" {
+		l.Config.TemplatesFolder = cfg.TemplatesFolder
+		l.Origins["templatesFolder"] = origin
+	}
+
+	for name, path := range cfg.Targets {
+		if l.Config.Targets == nil {
+			l.Config.Targets = make(map[string]string)
+		}
+		l.Config.Targets[name] = path
+		l.Origins["targets."+name] = origin
+	}
+
+	for name, outputs := range cfg.DefaultTargets {
+		if l.Config.DefaultTargets == nil {
+			l.Config.DefaultTargets = make(map[string][]string)
+		}
+		l.Config.DefaultTargets[name] = outputs
+		l.Origins["defaultTargets."+name] = origin
+	}
+
+	origins := make([]Origin, len(cfg.TemplateSources))
+	indexes := make([]int, len(cfg.TemplateSources))
+	for i := range origins {
+		origins[i] = origin
+		indexes[i] = i
+	}
+
+	l.Config.TemplateSources = slices.Concat(cfg.TemplateSources, l.Config.TemplateSources)
+	l.sourceOrigins = slices.Concat(origins, l.sourceOrigins)
+	l.sourceIndexes = slices.Concat(indexes, l.sourceIndexes)
+
+	if cfg.TemplatesFolder != "" {
+		l.folderTail = len(l.Config.TemplateSources)
+	}
+}
+
+// inheritTemplatesFolder keeps templates folder of inherited config available as a source
+// named after directory of that config, searched right before sources of that config
+func (l *Loaded) inheritTemplatesFolder() {
+	origin := l.Origins["templatesFolder"]
+	if origin.Layer != LayerProject {
+		return
+	}
+
+	source := TemplateSource{
+		Name: filepath.Base(filepath.Dir(origin.Source)),
+		Path: l.Config.TemplatesFolder,
+	}
+
+	i := len(l.Config.TemplateSources) - l.folderTail
+	l.Config.TemplateSources = slices.Insert(l.Config.TemplateSources, i, source)
+	l.sourceOrigins = slices.Insert(l.sourceOrigins, i, origin)
+	l.sourceIndexes = slices.Insert(l.sourceIndexes, i, -1)
+}
+
+func (l *Loaded) applyFile(cf configFile, layer Layer) {
+	resolvePaths(&cf.cfg, filepath.Dir(cf.path))
+	l.apply(&cf.cfg, Origin{Layer: layer, Source: cf.path})
+	l.Files = append(l.Files, cf.path)
+}
+
+// UserConfigFile returns path of user-level config file, e.g. ~/.config/flow/flow.yaml
+func UserConfigFile(baseName string) (string, bool) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+
+	return LookupConfig(filepath.Join(dir, "flow"), baseName)
+}
+
+// Load merges user config file, project config file (given in opts, FLOW_CONFIG
+// or the nearest one) with configs it inherits, FLOW_* environment variables and opts.
+// Project config file is not required if templates folder is set by another layer
+func Load(baseName string, opts Options) (*Loaded, error) {
+	l := &Loaded{
+		Config:  &Config{},
+		Origins: make(map[string]Origin),
+	}
+
+	if path, ok := UserConfigFile(baseName); ok {
+		cf, err := readConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		l.applyFile(cf, LayerUser)
+	}
+
+	projectFile := opts.ConfigFile
+	if projectFile == "" {
+		projectFile = os.Getenv(EnvConfig)
+	}
+
+	var findErr error
+	if projectFile == "" {
+		projectFile, findErr = findConfig(baseName)
+	}
+
+	if findErr == nil {
+		var err error
+		projectFile, err = filepath.Abs(projectFile)
+		if err != nil {
+			return nil, err
+		}
+
+		chain, err := projectChain(projectFile, baseName, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, cf := range chain {
+			if i > 0 && cf.cfg.TemplatesFolder != "" {
+				l.inheritTemplatesFolder()
+			}
+			l.applyFile(cf, LayerProject)
+		}
+
+		l.ProjectFile = projectFile
+		l.WorkspaceRoot = filepath.Dir(projectFile)
+		if chain[0].cfg.Root {
+			l.WorkspaceRoot = filepath.Dir(chain[0].path)
+		}
+	}
+
+	// Values of env and flags are relative to working directory
+	if v := os.Getenv(EnvTemplatesFolder); v != "" {
+		l.apply(&Config{TemplatesFolder: expandPath(v, "")}, Origin{Layer: LayerEnv, Source: EnvTemplatesFolder})
+	}
+
+	if opts.TemplatesDir != "" {
+		l.apply(
+			&Config{TemplatesFolder: expandPath(opts.TemplatesDir, "")},
+			Origin{Layer: LayerFlag, Source: "--templates-dir"},
+		)
+	}
+
+	if l.Config.TemplatesFolder == "" {
+		if findErr != nil {
+			return nil, findErr
+		}
+		return nil, fmt.Errorf("templatesFolder is not set in %s", projectFile)
+	}
+
+	for i, origin := range l.sourceOrigins {
+		l.Origins[fmt.Sprintf("templateSources[%d]", i)] = origin
+	}
+
+	return l, nil
+}
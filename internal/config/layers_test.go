@@ -0,0 +1,60 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"gotest.tools/v3/assert"
+)
+
+func TestLoadLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvConfig, "")
+	t.Setenv(config.EnvTemplatesFolder, "")
+
+	userDir, err := os.UserConfigDir()
+	assert.NilError(t, err)
+	assert.NilError(t, os.MkdirAll(filepath.Join(userDir, "flow"), 0o755))
+	userFile := filepath.Join(userDir, "flow", "flow.yaml")
+	assert.NilError(t, os.WriteFile(userFile, []byte(
+		"templatesFolder: ~/templates\ntemplateSources:\n  - name: personal\n    path: /opt/templates\n",
+	), 0o644))
+
+	project := t.TempDir()
+	projectFile := filepath.Join(project, "flow.json")
+	assert.NilError(t, os.WriteFile(projectFile, []byte(
+		`{"templatesFolder": ".flow", "templateSources": [{"name": "team", "path": "../shared"}]}`,
+	), 0o644))
+	// Relative paths are resolved against config file, not working directory
+	assert.NilError(t, os.Mkdir(filepath.Join(project, "sub"), 0o755))
+	t.Chdir(filepath.Join(project, "sub"))
+
+	l, err := config.Load("flow", config.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, l.ProjectFile, projectFile)
+	assert.Equal(t, l.Config.TemplatesFolder, filepath.Join(project, ".flow"))
+	assert.DeepEqual(t, l.Config.TemplateSources, []config.TemplateSource{
+		{Name: "team", Path: filepath.Join(filepath.Dir(project), "shared")},
+		{Name: "personal", Path: "/opt/templates"},
+	})
+	assert.DeepEqual(t, l.Origins, map[string]config.Origin{
+		"templatesFolder":    {Layer: config.LayerProject, Source: projectFile},
+		"templateSources[0]": {Layer: config.LayerProject, Source: projectFile},
+		"templateSources[1]": {Layer: config.LayerUser, Source: userFile},
+	})
+
+	t.Setenv(config.EnvTemplatesFolder, "~/env-templates")
+	l, err = config.Load("flow", config.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, l.Config.TemplatesFolder, filepath.Join(home, "env-templates"))
+	assert.Equal(t, l.Origins["templatesFolder"].Layer, config.LayerEnv)
+
+	l, err = config.Load("flow", config.Options{TemplatesDir: "flag-templates"})
+	assert.NilError(t, err)
+	assert.Equal(t, l.Config.TemplatesFolder, "flag-templates")
+	assert.Equal(t, l.Origins["templatesFolder"].Layer, config.LayerFlag)
+}
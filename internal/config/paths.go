@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandPath expands environment variables and leading ~ of p.
+// Relative result is joined to baseDir unless baseDir is empty
+func expandPath(p string, baseDir string) string {
+	p = os.ExpandEnv(p)
+
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+
+	if baseDir == "" || filepath.IsAbs(p) {
+		return p
+	}
+
+	return filepath.Join(baseDir, p)
+}
+
+// resolvePaths expands paths of cfg relative to baseDir. Path of git source
+// is located inside the repository, so only variables are expanded in it.
+// Targets referenced as @name in default targets are kept as is
+func resolvePaths(cfg *Config, baseDir string) {
+	if cfg.TemplatesFolder != "" {
+		cfg.TemplatesFolder = expandPath(cfg.TemplatesFolder, baseDir)
+	}
+
+	for i, s := range cfg.TemplateSources {
+		switch {
+		case s.Git != "":
+			s.Path = os.ExpandEnv(s.Path)
+		case s.Path != "":
+			s.Path = expandPath(s.Path, baseDir)
+		}
+
+		if archive, ok := strings.CutPrefix(s.Archive, "file://"); ok {
+			s.Archive = "file://" + expandPath(archive, baseDir)
+		} else if s.Archive != "" {
+			s.Archive = expandPath(s.Archive, baseDir)
+		}
+
+		cfg.TemplateSources[i] = s
+	}
+
+	for name, path := range cfg.Targets {
+		cfg.Targets[name] = expandPath(path, baseDir)
+	}
+
+	for name, outputs := range cfg.DefaultTargets {
+		resolved := make([]string, len(outputs))
+		for i, output := range outputs {
+			if strings.HasPrefix(output, TargetPrefix) {
+				resolved[i] = output
+			} else {
+				resolved[i] = expandPath(output, baseDir)
+			}
+		}
+		cfg.DefaultTargets[name] = resolved
+	}
+}
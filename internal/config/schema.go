@@ -0,0 +1,8 @@
+package config
+
+import _ "embed"
+
+// Schema is JSON Schema of config files, kept in sync with Config by tests
+//
+//go:embed schema.json
+var Schema []byte
@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"gotest.tools/v3/assert"
+)
+
+type schemaObject struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+type schema struct {
+	schemaObject
+	Defs map[string]schemaObject `json:"$defs"`
+}
+
+func jsonKeys(t reflect.Type) []string {
+	var keys []string
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys = append(keys, name)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func propertyKeys(o schemaObject) []string {
+	var keys []string
+	for k := range o.Properties {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestSchemaMatchesConfig(t *testing.T) {
+	t.Parallel()
+
+	var s schema
+	assert.NilError(t, json.Unmarshal(config.Schema, &s))
+
+	assert.DeepEqual(t, propertyKeys(s.schemaObject), jsonKeys(reflect.TypeFor[config.Config]()))
+	assert.DeepEqual(t, propertyKeys(s.Defs["templateSource"]), jsonKeys(reflect.TypeFor[config.TemplateSource]()))
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TargetPrefix marks output referring to a named target, e.g. @components
+const TargetPrefix = "@"
+
+// TargetNames returns names of targets in sorted order
+func (c *Config) TargetNames() []string {
+	names := make([]string, 0, len(c.Targets))
+	for name := range c.Targets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// DefaultOutputs returns default targets of template. Qualified name such as
+// "team:button" falls back to defaults of unqualified one
+func (c *Config) DefaultOutputs(templateName string) []string {
+	if outputs, ok := c.DefaultTargets[templateName]; ok {
+		return outputs
+	}
+
+	if i := strings.LastIndex(templateName, ":"); i >= 0 {
+		return c.DefaultTargets[templateName[i+1:]]
+	}
+
+	return nil
+}
+
+// ResolveOutputs replaces @target references in outputs with target paths.
+// Default targets of template are used if outputs are empty
+func (c *Config) ResolveOutputs(templateName string, outputs []string) ([]string, error) {
+	if len(outputs) == 0 {
+		outputs = c.DefaultOutputs(templateName)
+		if len(outputs) == 0 {
+			return nil, fmt.Errorf("no output paths given and template %s has no default targets", templateName)
+		}
+	}
+
+	res := make([]string, 0, len(outputs))
+	for _, output := range outputs {
+		name, ok := strings.CutPrefix(output, TargetPrefix)
+		if !ok {
+			res = append(res, output)
+			continue
+		}
+
+		path, ok := c.Targets[name]
+		if !ok {
+			return nil, c.unknownTarget(name)
+		}
+		res = append(res, path)
+	}
+
+	return res, nil
+}
+
+func (c *Config) unknownTarget(name string) error {
+	if len(c.Targets) == 0 {
+		return fmt.Errorf("unknown target %s%s, no targets are configured", TargetPrefix, name)
+	}
+
+	return fmt.Errorf("unknown target %s%s, must be one of: %s%s",
+		TargetPrefix, name, TargetPrefix, strings.Join(c.TargetNames(), ", "+TargetPrefix))
+}
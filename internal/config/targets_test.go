@@ -0,0 +1,58 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveOutputs(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{
+		Targets: map[string]string{
+			"components": "/repo/src/components",
+			"stories":    "/repo/src/stories",
+		},
+		DefaultTargets: map[string][]string{
+			"button": {"@components", "@stories"},
+			"page":   {"/repo/src/pages"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		outputs  []string
+		want     []string
+		err      string
+	}{
+		{name: "paths", template: "button", outputs: []string{"out"}, want: []string{"out"}},
+		{name: "alias", template: "page", outputs: []string{"@stories", "out"}, want: []string{"/repo/src/stories", "out"}},
+		{name: "defaults", template: "button", want: []string{"/repo/src/components", "/repo/src/stories"}},
+		{name: "qualified defaults", template: "team:page", want: []string{"/repo/src/pages"}},
+		{name: "no defaults", template: "modal", err: "template modal has no default targets"},
+		{
+			name:     "unknown alias",
+			template: "button",
+			outputs:  []string{"@hooks"},
+			err:      "unknown target @hooks, must be one of: @components, @stories",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := cfg.ResolveOutputs(tt.template, tt.outputs)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errorAt locates err at key of the layer origin came from.
+// Key is searched in config file, errors of env and flag layers are prefixed by their source
+func errorAt(origin Origin, key string, err error) error {
+	if origin.Layer == LayerEnv || origin.Layer == LayerFlag {
+		return fmt.Errorf("%s: %w", origin.Source, err)
+	}
+
+	data, readErr := os.ReadFile(origin.Source)
+	if readErr != nil {
+		return fmt.Errorf("%s: %w", origin.Source, err)
+	}
+
+	keyRe := regexp.MustCompile(`(?m)^[\s{,]*"?` + regexp.QuoteMeta(key) + `"?\s*:`)
+	loc := keyRe.FindIndex(data)
+	if loc == nil {
+		return fmt.Errorf("%s: %w", origin.Source, err)
+	}
+
+	line, _ := position(data, int64(loc[0]))
+	return &PositionError{File: origin.Source, Line: line, Err: err}
+}
+
+// sourceErrorAt locates err of template source listed at index of templateSources
+// in config file origin came from
+func sourceErrorAt(origin Origin, index int, err error) error {
+	err = fmt.Errorf("templateSources[%d]: %w", index, err)
+
+	if line, ok := sourceLine(origin.Source, index); ok {
+		return &PositionError{File: origin.Source, Line: line, Err: err}
+	}
+
+	return errorAt(origin, "templateSources", err)
+}
+
+// sourceLine returns line of template source listed at index of templateSources in config file
+func sourceLine(filename string, index int) (int, bool) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, false
+	}
+
+	// JSON is valid YAML once comments and trailing commas are gone
+	if filepath.Ext(filename) == ".jsonc" {
+		if data, err = stripJSONC(data); err != nil {
+			return 0, false
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return 0, false
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "templateSources" {
+			continue
+		}
+
+		sources := root.Content[i+1].Content
+		if index < 0 || index >= len(sources) {
+			return 0, false
+		}
+
+		return sources[index].Line, true
+	}
+
+	return 0, false
+}
+
+// Validate checks that templates folder exists, every template source has exactly one location
+// and default targets refer to configured targets
+func (l *Loaded) Validate() error {
+	var errs []error
+
+	info, err := os.Stat(l.Config.TemplatesFolder)
+	switch {
+	case err != nil:
+		errs = append(errs, errorAt(l.Origins["templatesFolder"], "templatesFolder",
+			fmt.Errorf("templates folder %s does not exist, run flow init or fix templatesFolder", l.Config.TemplatesFolder)))
+	case !info.IsDir():
+		errs = append(errs, errorAt(l.Origins["templatesFolder"], "templatesFolder",
+			fmt.Errorf("templates folder %s is not a directory", l.Config.TemplatesFolder)))
+	}
+
+	for i, s := range l.Config.TemplateSources {
+		var err error
+		switch {
+		case s.Git != "" && s.Archive != "":
+			err = errors.New("git and archive cannot be used together")
+		case s.Git == "" && s.Archive == "" && s.Path == "":
+			err = errors.New("one of path, git or archive is required")
+		case s.Git == "" && s.Ref != "":
+			err = errors.New("ref is only allowed for git sources")
+		}
+
+		if err != nil {
+			errs = append(errs, sourceErrorAt(l.sourceOrigins[i], l.sourceIndexes[i], err))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(l.Config.DefaultTargets)) {
+		key := "defaultTargets." + name
+		for _, output := range l.Config.DefaultTargets[name] {
+			target, ok := strings.CutPrefix(output, TargetPrefix)
+			if !ok {
+				continue
+			}
+
+			if _, ok := l.Config.Targets[target]; !ok {
+				errs = append(errs, errorAt(l.Origins[key], name, l.Config.unknownTarget(target)))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
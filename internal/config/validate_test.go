@@ -0,0 +1,63 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateTemplateSources(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvConfig, "")
+	t.Setenv(config.EnvTemplatesFolder, "")
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{
+			name: "yaml",
+			file: "flow.yaml",
+			content: "templatesFolder: templates\n" +
+				"templateSources:\n" +
+				"  - name: shared\n" +
+				"    path: shared\n" +
+				"  - name: team\n" +
+				"    git: https://example.com/team.git\n" +
+				"    archive: team.zip\n",
+			expected: "flow.yaml:5: templateSources[1]: git and archive cannot be used together",
+		},
+		{
+			name: "jsonc",
+			file: "flow.jsonc",
+			content: "{\n" +
+				"  \"templatesFolder\": \"templates\",\n" +
+				"  // sources\n" +
+				"  \"templateSources\": [\n" +
+				"    {\"path\": \"shared\", \"ref\": \"main\"},\n" +
+				"  ],\n" +
+				"}\n",
+			expected: "flow.jsonc:5: templateSources[0]: ref is only allowed for git sources",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			project := t.TempDir()
+			assert.NilError(t, os.Mkdir(filepath.Join(project, "templates"), 0o755))
+			file := filepath.Join(project, tc.file)
+			assert.NilError(t, os.WriteFile(file, []byte(tc.content), 0o644))
+
+			l, err := config.Load("flow", config.Options{ConfigFile: file})
+			assert.NilError(t, err)
+			assert.Error(t, l.Validate(), filepath.Join(project, tc.expected))
+		})
+	}
+}
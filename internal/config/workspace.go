@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type configFile struct {
+	path string
+	cfg  Config
+}
+
+func readConfig(path string) (configFile, error) {
+	var cfg Config
+	if err := ReadConfigFile(path, &cfg); err != nil {
+		return configFile{}, err
+	}
+
+	return configFile{path: path, cfg: cfg}, nil
+}
+
+// findConfigFrom searches for the config file in dir and its parents
+func findConfigFrom(dir string, baseName string) (string, bool) {
+	for {
+		if configPath, ok := LookupConfig(dir, baseName); ok {
+			return configPath, true
+		}
+
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return "", false
+		}
+		dir = parentDir
+	}
+}
+
+// resolveExtends returns config file extends of config located in dir points to.
+// Extends may name a config file or a directory containing one
+func resolveExtends(extends string, dir string, baseName string) (string, error) {
+	path := expandPath(extends, dir)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("extended config %s: %w", extends, err)
+	}
+
+	if !info.IsDir() {
+		return path, nil
+	}
+
+	configPath, ok := LookupConfig(path, baseName)
+	if !ok {
+		return "", fmt.Errorf("extended config %s: no %s config in directory", extends, baseName)
+	}
+
+	return configPath, nil
+}
+
+// projectChain returns config at path preceded by configs it inherits from, the most distant first.
+// Config inherits the one named by its extends. Otherwise, unless it is root, it inherits
+// the nearest config of parent directories if that one belongs to a workspace, i.e. its chain
+// starts with a root config. Configs outside of workspaces are not merged
+func projectChain(path string, baseName string, visiting []string) ([]configFile, error) {
+	if slices.Contains(visiting, path) {
+		return nil, fmt.Errorf("cyclic extends: %s", strings.Join(append(visiting, path), " -> "))
+	}
+	visiting = append(visiting, path)
+
+	cf, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+
+	if cf.cfg.Extends != "" {
+		parentPath, err := resolveExtends(cf.cfg.Extends, dir, baseName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		parents, err := projectChain(parentPath, baseName, visiting)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(parents, cf), nil
+	}
+
+	if cf.cfg.Root {
+		return []configFile{cf}, nil
+	}
+
+	parentPath, ok := findConfigFrom(filepath.Dir(dir), baseName)
+	if !ok || !inWorkspace(parentPath, baseName) {
+		return []configFile{cf}, nil
+	}
+
+	parents, err := projectChain(parentPath, baseName, visiting)
+	if err != nil {
+		return nil, err
+	}
+
+	if !parents[0].cfg.Root {
+		return []configFile{cf}, nil
+	}
+
+	return append(parents, cf), nil
+}
+
+// workspaceMarker holds the only values of config telling whether it belongs to a workspace
+type workspaceMarker struct {
+	Root    bool   `json:"root" yaml:"root"`
+	Extends string `json:"extends" yaml:"extends"`
+}
+
+// inWorkspace leniently reads config at path and reports whether it belongs to a workspace:
+// it is root, extends another config or inherits a root. Configs which cannot be read,
+// e.g. malformed ones or those of another tool named alike, do not belong to any
+func inWorkspace(path string, baseName string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var m workspaceMarker
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(data, &m)
+	case ".jsonc":
+		if data, err = stripJSONC(data); err == nil {
+			err = json.Unmarshal(data, &m)
+		}
+	default:
+		err = yaml.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return false
+	}
+
+	if m.Root || m.Extends != "" {
+		return true
+	}
+
+	parentPath, ok := findConfigFrom(filepath.Dir(filepath.Dir(path)), baseName)
+	return ok && inWorkspace(parentPath, baseName)
+}
+
+// skippedDirs are never searched for workspace configs
+var skippedDirs = []string{"node_modules", "vendor"}
+
+// WorkspaceConfigs returns every config file located under workspace root dir, root one included
+func WorkspaceConfigs(root string, baseName string) ([]string, error) {
+	var configs []string
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root && (strings.HasPrefix(d.Name(), ".") || slices.Contains(skippedDirs, d.Name())) {
+			return filepath.SkipDir
+		}
+
+		if configPath, ok := LookupConfig(path, baseName); ok {
+			configs = append(configs, configPath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search workspace: %w", err)
+	}
+
+	return configs, nil
+}
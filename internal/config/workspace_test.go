@@ -0,0 +1,127 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/config"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func isolate(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvConfig, "")
+	t.Setenv(config.EnvTemplatesFolder, "")
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	isolate(t)
+
+	ws := fs.NewDir(t, "ws",
+		fs.WithFile("flow.yaml", "root: true\ntemplatesFolder: .flow\ntemplateSources:\n  - name: team\n    path: shared\n"),
+		fs.WithDir("packages",
+			fs.WithDir("ui",
+				fs.WithFile("flow.yaml", "templateSources:\n  - name: ui\n    path: templates\n"),
+				fs.WithDir("src"),
+			),
+			fs.WithDir("api", fs.WithFile("flow.json", `{"extends": "../ui", "templatesFolder": ".api"}`)),
+		),
+		fs.WithDir("node_modules", fs.WithDir("dep", fs.WithFile("flow.yaml", "templatesFolder: x\n"))),
+	)
+	root := ws.Path()
+	ui := filepath.Join(root, "packages", "ui")
+
+	t.Chdir(filepath.Join(ui, "src"))
+
+	l, err := config.Load("flow", config.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, l.WorkspaceRoot, root)
+	assert.Equal(t, l.Config.TemplatesFolder, filepath.Join(root, ".flow"))
+	assert.DeepEqual(t, l.Config.TemplateSources, []config.TemplateSource{
+		{Name: "ui", Path: filepath.Join(ui, "templates")},
+		{Name: "team", Path: filepath.Join(root, "shared")},
+	})
+	assert.DeepEqual(t, l.Files, []string{filepath.Join(root, "flow.yaml"), filepath.Join(ui, "flow.yaml")})
+
+	api := filepath.Join(root, "packages", "api", "flow.json")
+	l, err = config.Load("flow", config.Options{ConfigFile: api})
+	assert.NilError(t, err)
+	assert.Equal(t, l.Config.TemplatesFolder, filepath.Join(root, "packages", "api", ".api"))
+	// Overridden templates folder of the workspace root stays available
+	assert.DeepEqual(t, l.Config.TemplateSources, []config.TemplateSource{
+		{Name: "ui", Path: filepath.Join(ui, "templates")},
+		{Name: filepath.Base(root), Path: filepath.Join(root, ".flow")},
+		{Name: "team", Path: filepath.Join(root, "shared")},
+	})
+
+	configs, err := config.WorkspaceConfigs(root, "flow")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, configs, []string{
+		filepath.Join(root, "flow.yaml"),
+		api,
+		filepath.Join(ui, "flow.yaml"),
+	})
+}
+
+func TestLoadNotWorkspace(t *testing.T) {
+	isolate(t)
+
+	// Parent config without root is not inherited
+	dir := fs.NewDir(t, "outer",
+		fs.WithFile("flow.yaml", "templatesFolder: .flow\ntemplateSources:\n  - path: shared\n"),
+		fs.WithDir("pkg", fs.WithFile("flow.yaml", "templatesFolder: .flow\n")),
+	)
+	t.Chdir(filepath.Join(dir.Path(), "pkg"))
+
+	l, err := config.Load("flow", config.Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, l.Config.TemplatesFolder, filepath.Join(dir.Path(), "pkg", ".flow"))
+	assert.Equal(t, len(l.Config.TemplateSources), 0)
+	assert.Equal(t, l.WorkspaceRoot, filepath.Join(dir.Path(), "pkg"))
+}
+
+func TestLoadUnrelatedParent(t *testing.T) {
+	isolate(t)
+
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{name: "other tool", file: "flow.json", data: `{"root": "src", "tasks": []}`},
+		{name: "malformed", file: "flow.yaml", data: "root: [true\n"},
+		{name: "unknown keys", file: "flow.yaml", data: "tasks: []\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Parent config which does not belong to a workspace is not even validated
+			dir := fs.NewDir(t, "outer",
+				fs.WithFile(tc.file, tc.data),
+				fs.WithDir("pkg", fs.WithFile("flow.yaml", "templatesFolder: .flow\n")),
+			)
+			t.Chdir(filepath.Join(dir.Path(), "pkg"))
+
+			l, err := config.Load("flow", config.Options{})
+			assert.NilError(t, err)
+			assert.Equal(t, l.Config.TemplatesFolder, filepath.Join(dir.Path(), "pkg", ".flow"))
+			assert.DeepEqual(t, l.Files, []string{filepath.Join(dir.Path(), "pkg", "flow.yaml")})
+		})
+	}
+}
+
+func TestLoadExtendsCycle(t *testing.T) {
+	isolate(t)
+
+	dir := fs.NewDir(t, "cycle",
+		fs.WithDir("a", fs.WithFile("flow.yaml", "extends: ../b\n")),
+		fs.WithDir("b", fs.WithFile("flow.yaml", "extends: ../a/flow.yaml\n")),
+	)
+
+	_, err := config.Load("flow", config.Options{ConfigFile: filepath.Join(dir.Path(), "a", "flow.yaml")})
+	assert.ErrorContains(t, err, "cyclic extends")
+}
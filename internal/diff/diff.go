@@ -0,0 +1,236 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// Line numbers (starting from 1) in old and new text after this op is applied
+	oldLine int
+	newLine int
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// maxCells limits size of the LCS table. Changed regions needing a larger one
+// are not compared line by line
+const maxCells = 1 << 22
+
+// lineOps computes line operations turning a into b using longest common subsequence
+// of lines between common prefix and suffix. Returns false if they are too large to compare
+func lineOps(a, b []string) ([]op, bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	endA, endB := len(a)-suffix, len(b)-suffix
+	if (endA-prefix+1)*(endB-prefix+1) > maxCells {
+		return nil, false
+	}
+
+	// lcs[i][j] is the LCS length of a[prefix+i:endA] and b[prefix+j:endB]
+	lcs := make([][]int, endA-prefix+1)
+	for i := range lcs {
+		lcs[i] = make([]int, endB-prefix+1)
+	}
+
+	for i := endA - prefix - 1; i >= 0; i-- {
+		for j := endB - prefix - 1; j >= 0; j-- {
+			if a[prefix+i] == b[prefix+j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < prefix {
+		i++
+		j++
+		ops = append(ops, op{kind: opEqual, line: a[i-1], oldLine: i, newLine: j})
+	}
+
+	for i < endA || j < endB {
+		switch {
+		case i < endA && j < endB && a[i] == b[j]:
+			i++
+			j++
+			ops = append(ops, op{kind: opEqual, line: a[i-1], oldLine: i, newLine: j})
+		case i < endA && (j == endB || lcs[i+1-prefix][j-prefix] >= lcs[i-prefix][j+1-prefix]):
+			i++
+			ops = append(ops, op{kind: opDelete, line: a[i-1], oldLine: i, newLine: j})
+		default:
+			j++
+			ops = append(ops, op{kind: opInsert, line: b[j-1], oldLine: i, newLine: j})
+		}
+	}
+
+	for i < len(a) {
+		i++
+		j++
+		ops = append(ops, op{kind: opEqual, line: a[i-1], oldLine: i, newLine: j})
+	}
+
+	return ops, true
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeHunk(sb *strings.Builder, ops []op) {
+	// Line numbers before the first op of hunk
+	oldStart, newStart := ops[0].oldLine, ops[0].newLine
+	switch ops[0].kind {
+	case opEqual:
+		oldStart--
+		newStart--
+	case opDelete:
+		oldStart--
+	case opInsert:
+		newStart--
+	}
+
+	var oldCount, newCount int
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops {
+		sb.WriteByte(byte(o.kind))
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// Unified returns unified diff between oldText and newText
+// with file headers oldName and newName. Returns empty string if texts are equal
+// and only a summary line if changed parts are too large to compare
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops, ok := lineOps(splitLines(oldText), splitLines(newText))
+	if !ok {
+		return fmt.Sprintf("Files %s and %s differ, too many changed lines to show\n", oldName, newName)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(ops); {
+		// Find next change
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend hunk while changes are separated by no more than 2*contextLines equal lines
+		last := first
+		for k := first; k < len(ops); k++ {
+			if ops[k].kind != opEqual {
+				last = k
+			} else if k-last > 2*contextLines {
+				break
+			}
+		}
+
+		from := max(first-contextLines, start)
+		to := min(last+contextLines+1, len(ops))
+		writeHunk(&sb, ops[from:to])
+		start = to
+	}
+
+	return sb.String()
+}
+
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+	colorBold  = "\033[1m"
+)
+
+// Colorize wraps lines of unified diff in ANSI color codes
+func Colorize(diff string) string {
+	lines := splitLines(diff)
+
+	var sb strings.Builder
+	for _, line := range lines {
+		var color string
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		}
+
+		if color == "" {
+			sb.WriteString(line)
+			continue
+		}
+
+		sb.WriteString(color)
+		sb.WriteString(strings.TrimSuffix(line, "\n"))
+		sb.WriteString(colorReset)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/diff"
+	"gotest.tools/v3/assert"
+)
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "new file content",
+			old:  "",
+			new:  "a\nb\n",
+			expected: "--- a\n+++ b\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n" +
+				"+b\n",
+		},
+		{
+			name: "changed line",
+			old:  "a\nb\nc\n",
+			new:  "a\nx\nc\n",
+			expected: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" a\n" +
+				"-b\n" +
+				"+x\n" +
+				" c\n",
+		},
+		{
+			name: "distant changes",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "0\n2\n3\n4\n5\n6\n7\n8\n9\n11\n",
+			expected: "--- a\n+++ b\n" +
+				"@@ -1,4 +1,4 @@\n" +
+				"-1\n" +
+				"+0\n" +
+				" 2\n" +
+				" 3\n" +
+				" 4\n" +
+				"@@ -7,4 +7,4 @@\n" +
+				" 7\n" +
+				" 8\n" +
+				" 9\n" +
+				"-10\n" +
+				"+11\n",
+		},
+		{
+			name: "missing newline",
+			old:  "a",
+			new:  "b",
+			expected: "--- a\n+++ b\n" +
+				"@@ -1 +1 @@\n" +
+				"-a\n\\ No newline at end of file\n" +
+				"+b\n\\ No newline at end of file\n",
+		},
+		{
+			name: "change in large file",
+			old:  strings.Repeat("x\n", 20000) + "a\n" + strings.Repeat("x\n", 20000),
+			new:  strings.Repeat("x\n", 20000) + "b\n" + strings.Repeat("x\n", 20000),
+			expected: "--- a\n+++ b\n" +
+				"@@ -19998,7 +19998,7 @@\n" +
+				" x\n x\n x\n" +
+				"-a\n" +
+				"+b\n" +
+				" x\n x\n x\n",
+		},
+		{
+			name:     "large rewrite",
+			old:      strings.Repeat("x\n", 20000),
+			new:      strings.Repeat("y\n", 20000),
+			expected: "Files a and b differ, too many changed lines to show\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, diff.Unified("a", "b", tc.old, tc.new), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+type Runner struct{}
+
+func New() *Runner {
+	return &Runner{}
+}
+
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+
+	return exec.Command("sh", "-c", command) // nolint: gosec
+}
+
+// Run executes command with system shell in dir, streaming its output to out.
+// env is appended to the environment of current process
+func (r Runner) Run(command string, dir string, env []string, out io.Writer) error {
+	cmd := shellCommand(command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %q: %w", command, err)
+	}
+
+	return nil
+}
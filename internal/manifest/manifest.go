@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of optional manifest file located in template root.
+// Manifest itself is never generated
+const FileName = "template.yaml"
+
+const (
+	TypeString  = "string"
+	TypeBoolean = "boolean"
+)
+
+var types = []string{TypeString, TypeBoolean}
+
+// Variable declares template variable. All fields are optional
+type Variable struct {
+	Type        string   `yaml:"type,omitempty"`
+	Default     *string  `yaml:"default,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Enum        []string `yaml:"enum,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+}
+
+// Hooks are shell commands run in every output directory around generation.
+// Variables are available to them as FLOW_VAR_<name> environment variables
+type Hooks struct {
+	PreGenerate  []string `yaml:"preGenerate,omitempty"`
+	PostGenerate []string `yaml:"postGenerate,omitempty"`
+	// RollbackOnFailure removes generated files if any post-generate hook fails
+	RollbackOnFailure bool `yaml:"rollbackOnFailure,omitempty"`
+}
+
+type Manifest struct {
+	// Extends lists templates whose files, variables and hooks are inherited, looked up in
+	// the same template source unless qualified as <source>:<name>. Later templates and the template itself win
+	Extends     []string            `yaml:"extends,omitempty"`
+	Description string              `yaml:"description,omitempty"`
+	Tags        []string            `yaml:"tags,omitempty"`
+	Variables   map[string]Variable `yaml:"variables,omitempty"`
+	Hooks       Hooks               `yaml:"hooks,omitempty"`
+}
+
+func (v Variable) validate() error {
+	if v.Type != "" && !slices.Contains(types, v.Type) {
+		return fmt.Errorf("unknown type %q", v.Type)
+	}
+
+	var pattern *regexp.Regexp
+	if v.Pattern != "" {
+		var err error
+		pattern, err = regexp.Compile(v.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	if v.Default != nil {
+		if len(v.Enum) > 0 && !slices.Contains(v.Enum, *v.Default) {
+			return fmt.Errorf("default %q is not one of enum values", *v.Default)
+		}
+
+		if pattern != nil && !pattern.MatchString(*v.Default) {
+			return fmt.Errorf("default %q does not match pattern %s", *v.Default, v.Pattern)
+		}
+	}
+
+	return nil
+}
+
+// Marshal encodes manifest as YAML
+func Marshal(m *Manifest) ([]byte, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+
+	return data, nil
+}
+
+// Parse decodes and validates manifest, unknown fields are rejected
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	for name, v := range m.Variables {
+		if err := v.validate(); err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,74 @@
+package manifest_test
+
+import (
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"gotest.tools/v3/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name: "valid",
+			input: `description: React component
+variables:
+  name:
+    description: Component name
+    pattern: ^[A-Z]
+  style:
+    enum: [css, scss]
+    default: css
+  withTests:
+    type: boolean
+    default: true
+`,
+		},
+		{
+			name:  "unknown field",
+			input: "variables:\n  name:\n    desc: Component name\n",
+			err:   "field desc not found",
+		},
+		{
+			name:  "unknown type",
+			input: "variables:\n  name:\n    type: int\n",
+			err:   `variable name: unknown type "int"`,
+		},
+		{
+			name:  "invalid pattern",
+			input: "variables:\n  name:\n    pattern: \"[\"\n",
+			err:   "variable name: invalid pattern",
+		},
+		{
+			name:  "default outside of enum",
+			input: "variables:\n  style:\n    enum: [css]\n    default: less\n",
+			err:   `variable style: default "less" is not one of enum values`,
+		},
+		{
+			name:  "default not matching pattern",
+			input: "variables:\n  name:\n    pattern: ^[A-Z]\n    default: button\n",
+			err:   `variable name: default "button" does not match pattern ^[A-Z]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := manifest.Parse([]byte(tc.input))
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+// zipFS is zip.Reader able to read symbolic links, whose targets are stored as content
+type zipFS struct {
+	*zip.Reader
+}
+
+func (z zipFS) ReadLink(name string) (string, error) {
+	f, err := z.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	target, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	return string(target), nil
+}
+
+// node is a file or directory of tarFS
+type node struct {
+	name     string
+	mode     iofs.FileMode
+	size     int64
+	modTime  time.Time
+	link     string
+	children []*node
+	// content of text files, binary ones are streamed from archive on open
+	content []byte
+	binary  bool
+}
+
+func (n *node) Name() string                 { return n.name }
+func (n *node) Size() int64                  { return n.size }
+func (n *node) Mode() iofs.FileMode          { return n.mode }
+func (n *node) ModTime() time.Time           { return n.modTime }
+func (n *node) IsDir() bool                  { return n.mode.IsDir() }
+func (n *node) Sys() any                     { return nil }
+func (n *node) Type() iofs.FileMode          { return n.mode.Type() }
+func (n *node) Info() (iofs.FileInfo, error) { return n, nil }
+
+// tarFS is read-only file system of .tar.gz archive. Archive is indexed once,
+// text files are kept in memory and binary ones are read from archive on demand
+type tarFS struct {
+	path  string
+	nodes map[string]*node
+}
+
+func newTarFS(archivePath string) (*tarFS, error) {
+	t := &tarFS{
+		path: archivePath,
+		nodes: map[string]*node{
+			".": {name: ".", mode: iofs.ModeDir | 0o755},
+		},
+	}
+
+	err := walkTar(archivePath, func(hdr *tar.Header, tr *tar.Reader) error {
+		name := path.Clean(hdr.Name)
+		if !iofs.ValidPath(name) || name == "." {
+			return nil
+		}
+
+		n := &node{
+			name:    path.Base(name),
+			mode:    os.FileMode(hdr.Mode).Perm(),
+			size:    hdr.Size,
+			modTime: hdr.ModTime,
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			n.mode |= iofs.ModeDir
+		case tar.TypeSymlink:
+			n.mode |= iofs.ModeSymlink
+			n.link = hdr.Linkname
+		case tar.TypeReg:
+			source, binary, err := fs.ReadSource(tr)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			n.content = []byte(source)
+			n.binary = binary
+		default:
+			return nil
+		}
+
+		t.add(name, n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// add places n at name, creating missing parent dirs
+func (t *tarFS) add(name string, n *node) {
+	if existing, ok := t.nodes[name]; ok {
+		// Dir created for earlier children gets its own header data
+		n.children = existing.children
+		*existing = *n
+		return
+	}
+
+	parentName := path.Dir(name)
+	parent, ok := t.nodes[parentName]
+	if !ok {
+		parent = &node{name: path.Base(parentName), mode: iofs.ModeDir | 0o755}
+		t.add(parentName, parent)
+	}
+
+	parent.children = append(parent.children, n)
+	t.nodes[name] = n
+}
+
+func (t *tarFS) lookup(op string, name string) (*node, error) {
+	n, ok := t.nodes[name]
+	if !ok || !iofs.ValidPath(name) {
+		return nil, &iofs.PathError{Op: op, Path: name, Err: iofs.ErrNotExist}
+	}
+
+	return n, nil
+}
+
+func (t *tarFS) Open(name string) (iofs.File, error) {
+	n, err := t.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case n.IsDir():
+		return &dirFile{node: n}, nil
+	case n.binary:
+		return t.stream(name, n)
+	default:
+		return &memFile{node: n, Reader: bytes.NewReader(n.content)}, nil
+	}
+}
+
+func (t *tarFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	n, err := t.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return (&dirFile{node: n}).ReadDir(-1)
+}
+
+func (t *tarFS) ReadLink(name string) (string, error) {
+	n, err := t.lookup("readlink", name)
+	if err != nil {
+		return "", err
+	}
+
+	if n.mode&iofs.ModeSymlink == 0 {
+		return "", &iofs.PathError{Op: "readlink", Path: name, Err: iofs.ErrInvalid}
+	}
+
+	return n.link, nil
+}
+
+// stream reopens archive and returns file reading content of entry name from it
+func (t *tarFS) stream(name string, n *node) (iofs.File, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+
+	closeAll := func() error {
+		return errors.Join(gz.Close(), f.Close())
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.Join(&iofs.PathError{Op: "open", Path: name, Err: iofs.ErrNotExist}, closeAll())
+		}
+		if err != nil {
+			return nil, errors.Join(err, closeAll())
+		}
+
+		if path.Clean(hdr.Name) == name {
+			return &streamFile{node: n, Reader: tr, close: closeAll}, nil
+		}
+	}
+}
+
+type memFile struct {
+	*node
+	*bytes.Reader
+}
+
+func (f *memFile) Stat() (iofs.FileInfo, error) { return f.node, nil }
+func (f *memFile) Close() error                 { return nil }
+
+type streamFile struct {
+	*node
+	io.Reader
+	close func() error
+}
+
+func (f *streamFile) Stat() (iofs.FileInfo, error) { return f.node, nil }
+func (f *streamFile) Close() error                 { return f.close() }
+
+type dirFile struct {
+	*node
+	offset int
+}
+
+func (d *dirFile) Stat() (iofs.FileInfo, error) { return d.node, nil }
+func (d *dirFile) Close() error                 { return nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &iofs.PathError{Op: "read", Path: d.name, Err: iofs.ErrInvalid}
+}
+
+func (d *dirFile) ReadDir(count int) ([]iofs.DirEntry, error) {
+	children := slices.Clone(d.children)
+	slices.SortFunc(children, func(a, b *node) int {
+		return cmp.Compare(a.name, b.name)
+	})
+
+	rest := children[d.offset:]
+	if count > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > 0 && count < len(rest) {
+		rest = rest[:count]
+	}
+	d.offset += len(rest)
+
+	entries := make([]iofs.DirEntry, len(rest))
+	for i, n := range rest {
+		entries[i] = n
+	}
+
+	return entries, nil
+}
+
+// walkTar calls fn for each header of .tar.gz archive
+func walkTar(archivePath string, fn func(*tar.Header, *tar.Reader) error) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if err := fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package archive
+
+import (
+	"archive/zip"
+	"fmt"
+	iofs "io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+type Format string
+
+const (
+	FormatTarGz Format = "tar.gz"
+	FormatZip   Format = "zip"
+)
+
+// FormatOf detects archive format by file extension
+func FormatOf(filename string) (Format, error) {
+	switch {
+	case strings.HasSuffix(filename, ".tar.gz"), strings.HasSuffix(filename, ".tgz"):
+		return FormatTarGz, nil
+	case strings.HasSuffix(filename, ".zip"):
+		return FormatZip, nil
+	default:
+		return "", fmt.Errorf("unsupported archive %s, expected .tar.gz, .tgz or .zip", filename)
+	}
+}
+
+// TrimExt returns base name of archive without its extension, e.g. "ui" for "dist/ui.tar.gz"
+func TrimExt(filename string) string {
+	name := filepath.Base(filename)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if s, ok := strings.CutSuffix(name, ext); ok {
+			return s
+		}
+	}
+
+	return name
+}
+
+// ArchiveRepo serves templates packed into a .tar.gz or .zip archive, where every
+// top-level directory is a template. Files are read straight from the archive,
+// which is opened on first use
+type ArchiveRepo struct {
+	path   string
+	format Format
+	repo   *templates.FSRepo
+}
+
+// New creates repo for archive located at local path or file:// url
+func New(location string) (*ArchiveRepo, error) {
+	p := strings.TrimPrefix(location, "file://")
+
+	format, err := FormatOf(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArchiveRepo{
+		path:   p,
+		format: format,
+	}, nil
+}
+
+func (r *ArchiveRepo) open() (*templates.FSRepo, error) {
+	if r.repo != nil {
+		return r.repo, nil
+	}
+
+	var (
+		fsys iofs.FS
+		err  error
+	)
+	if r.format == FormatZip {
+		// Archive stays open for lifetime of the process as binary files are streamed from it
+		var zr *zip.ReadCloser
+		zr, err = zip.OpenReader(r.path)
+		if err == nil {
+			fsys = zipFS{Reader: &zr.Reader}
+		}
+	} else {
+		fsys, err = newTarFS(r.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", r.path, err)
+	}
+
+	r.repo = templates.NewFS(fsys)
+	return r.repo, nil
+}
+
+func (r *ArchiveRepo) GetTemplatesNames() ([]string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetTemplatesNames()
+}
+
+func (r *ArchiveRepo) GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error) {
+	repo, err := r.open()
+	if err != nil {
+		return fs.Dir{}, nil, err
+	}
+
+	return repo.GetTemplate(templateName)
+}
+
+func (r *ArchiveRepo) CreateTemplate(string, fs.Dir) error {
+	return templates.ErrReadOnly
+}
+
+func (r *ArchiveRepo) RemoveTemplate(string) error {
+	return templates.ErrReadOnly
+}
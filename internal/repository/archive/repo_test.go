@@ -0,0 +1,94 @@
+package archive_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/archive"
+	flowfs "github.com/flowtemplates/flow-cli/pkg/fs"
+	"gotest.tools/v3/assert"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte{0x89, 'P', 'N', 'G', 0x00, 0x01}
+	dir := flowfs.Dir{
+		Name: ".",
+		Path: ".",
+		Files: []flowfs.File{
+			{Name: "template.yaml", Path: "template.yaml", Source: "description: Button\n"},
+			{Name: "{{name}}.ts.ft", Path: "{{name}}.ts.ft", Source: "export {{name}}\n", Mode: 0o755},
+		},
+		Dirs: []flowfs.Dir{{
+			Name: "assets",
+			Path: ".",
+			Files: []flowfs.File{
+				{
+					Name:   "icon.png",
+					Path:   filepath.Join("assets", "icon.png"),
+					Binary: true,
+					Size:   int64(len(binary)),
+					Open: func() (io.ReadCloser, error) {
+						return io.NopCloser(bytes.NewReader(binary)), nil
+					},
+				},
+				{Name: "link", Path: filepath.Join("assets", "link"), LinkTarget: "icon.png"},
+			},
+		}},
+	}
+
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		t.Run(ext, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "pack"+ext)
+			format, err := archive.FormatOf(path)
+			assert.NilError(t, err)
+
+			f, err := os.Create(path)
+			assert.NilError(t, err)
+			assert.NilError(t, archive.Write(f, format, "button", dir))
+			assert.NilError(t, f.Close())
+
+			r, err := archive.New("file://" + path)
+			assert.NilError(t, err)
+
+			names, err := r.GetTemplatesNames()
+			assert.NilError(t, err)
+			assert.DeepEqual(t, names, []string{"button"})
+
+			got, m, err := r.GetTemplate("button")
+			assert.NilError(t, err)
+			assert.Equal(t, m.Description, "Button")
+
+			assert.Equal(t, len(got.Files), 1)
+			assert.Equal(t, got.Files[0].Source, "export {{name}}\n")
+			assert.Equal(t, got.Files[0].Mode, os.FileMode(0o755))
+
+			assert.Equal(t, len(got.Dirs), 1)
+			assets := got.Dirs[0]
+			assert.Equal(t, assets.Name, "assets")
+			assert.Equal(t, assets.Path, ".")
+			assert.Equal(t, len(assets.Files), 2)
+
+			icon := assets.Files[0]
+			assert.Equal(t, icon.Path, filepath.Join("assets", "icon.png"))
+			assert.Assert(t, icon.Binary)
+			rc, err := icon.Open()
+			assert.NilError(t, err)
+			content, err := io.ReadAll(rc)
+			assert.NilError(t, err)
+			assert.NilError(t, rc.Close())
+			assert.DeepEqual(t, content, binary)
+
+			assert.Equal(t, assets.Files[1].LinkTarget, "icon.png")
+
+			_, _, err = r.GetTemplate("missing")
+			assert.ErrorIs(t, err, os.ErrNotExist)
+		})
+	}
+}
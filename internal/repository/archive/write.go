@@ -0,0 +1,163 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+// Write packs dir as template templateName into archive of given format written to w
+func Write(w io.Writer, format Format, templateName string, dir fs.Dir) error {
+	if format == FormatZip {
+		return writeZip(w, templateName, dir)
+	}
+
+	return writeTarGz(w, templateName, dir)
+}
+
+// walk calls fn for every dir and file of dir with slash separated paths prefixed by root
+func walk(root string, dir fs.Dir, fn func(name string, file *fs.File) error) error {
+	for _, f := range dir.Files {
+		if err := fn(path.Join(root, filepath.ToSlash(f.Path)), &f); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range dir.Dirs {
+		name := path.Join(root, filepath.ToSlash(filepath.Join(d.Path, d.Name)))
+		if err := fn(name, nil); err != nil {
+			return err
+		}
+
+		if err := walk(root, d, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fileMode(file *fs.File) os.FileMode {
+	if file.Mode == 0 {
+		return fs.DefaultFileMode
+	}
+
+	return file.Mode
+}
+
+// content returns reader over file content
+func content(file *fs.File) (io.ReadCloser, error) {
+	if file.Binary {
+		return file.Open()
+	}
+
+	return io.NopCloser(strings.NewReader(file.Source)), nil
+}
+
+func writeZip(w io.Writer, templateName string, dir fs.Dir) error {
+	zw := zip.NewWriter(w)
+
+	err := walk(templateName, dir, func(name string, file *fs.File) error {
+		if file == nil {
+			hdr := &zip.FileHeader{Name: name + "/"}
+			hdr.SetMode(os.ModeDir | 0o755)
+			_, err := zw.CreateHeader(hdr)
+			return err
+		}
+
+		hdr := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		if file.IsLink() {
+			hdr.SetMode(os.ModeSymlink | 0o777)
+			fw, err := zw.CreateHeader(hdr)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(fw, file.LinkTarget)
+			return err
+		}
+
+		hdr.SetMode(fileMode(file))
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+
+		src, err := content(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(fw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeTarGz(w io.Writer, templateName string, dir fs.Dir) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := walk(templateName, dir, func(name string, file *fs.File) error {
+		if file == nil {
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     name + "/",
+				Mode:     0o755,
+			})
+		}
+
+		if file.IsLink() {
+			return tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeSymlink,
+				Name:     name,
+				Linkname: file.LinkTarget,
+				Mode:     0o777,
+			})
+		}
+
+		// Tar header needs the size up front
+		size := int64(len(file.Source))
+		if file.Binary {
+			size = file.Size
+		}
+
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(fileMode(file)),
+			Size:     size,
+		}); err != nil {
+			return err
+		}
+
+		src, err := content(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
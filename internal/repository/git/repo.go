@@ -0,0 +1,177 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+const defaultRef = "HEAD"
+
+// GitRepo serves templates from a git repository checked out into a local cache.
+// Checkout is created on first use and only changes on Update, so generations are reproducible
+type GitRepo struct {
+	url      string
+	ref      string
+	subdir   string
+	cacheDir string
+	// cloneErr is the error of the first clone made on use, kept so that lookups
+	// in all sources do not clone an unreachable remote over and over
+	cloneErr error
+}
+
+// New creates repo for git url at ref (branch, tag or commit), reading templates from subdir of it.
+// Checkout is located in a directory of cacheRoot unique for url and ref, so sources
+// pinned to different refs of the same repository never share it
+func New(url string, ref string, subdir string, cacheRoot string) *GitRepo {
+	if ref == "" {
+		ref = defaultRef
+	}
+
+	hash := sha256.Sum256([]byte(url + "\x00" + ref))
+
+	return &GitRepo{
+		url:      url,
+		ref:      ref,
+		subdir:   subdir,
+		cacheDir: filepath.Join(cacheRoot, hex.EncodeToString(hash[:8])),
+	}
+}
+
+// DefaultCacheRoot returns directory where git sources are checked out
+func DefaultCacheRoot() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache dir: %w", err)
+	}
+
+	return filepath.Join(dir, "flow", "git"), nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (r *GitRepo) cloned() bool {
+	_, err := os.Stat(filepath.Join(r.cacheDir, ".git"))
+	return err == nil
+}
+
+// Update fetches ref from remote and checks it out, returning checked out commit.
+// First checkout is made in a temporary directory moved into cache only once it succeeded,
+// so a failed clone is retried on next Update
+func (r *GitRepo) Update() (string, error) {
+	if r.cloned() {
+		if err := checkout(r.cacheDir, r.ref); err != nil {
+			return "", err
+		}
+
+		return r.Commit()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.cacheDir), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(r.cacheDir), filepath.Base(r.cacheDir)+".tmp-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := git(tmp, "init", "--quiet"); err != nil {
+		return "", err
+	}
+
+	if _, err := git(tmp, "remote", "add", "origin", r.url); err != nil {
+		return "", err
+	}
+
+	if err := checkout(tmp, r.ref); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, r.cacheDir); err != nil {
+		return "", fmt.Errorf("failed to move checkout into cache: %w", err)
+	}
+
+	return r.Commit()
+}
+
+// checkout fetches ref into repository at dir and checks it out
+func checkout(dir string, ref string) error {
+	if _, err := git(dir, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+		return err
+	}
+
+	if _, err := git(dir, "checkout", "--quiet", "--force", "--detach", "FETCH_HEAD"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Commit returns currently checked out commit
+func (r *GitRepo) Commit() (string, error) {
+	return git(r.cacheDir, "rev-parse", "HEAD")
+}
+
+func (r *GitRepo) templates() (*templates.TemplatesRepo, error) {
+	if !r.cloned() {
+		if r.cloneErr != nil {
+			return nil, r.cloneErr
+		}
+
+		if _, err := r.Update(); err != nil {
+			r.cloneErr = fmt.Errorf("failed to clone %s: %w", r.url, err)
+			return nil, r.cloneErr
+		}
+	}
+
+	return templates.New(filepath.Join(r.cacheDir, r.subdir)), nil
+}
+
+func (r *GitRepo) GetTemplatesNames() ([]string, error) {
+	tr, err := r.templates()
+	if err != nil {
+		return nil, err
+	}
+
+	return tr.GetTemplatesNames()
+}
+
+func (r *GitRepo) GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error) {
+	tr, err := r.templates()
+	if err != nil {
+		return fs.Dir{}, nil, err
+	}
+
+	return tr.GetTemplate(templateName)
+}
+
+func (r *GitRepo) CreateTemplate(string, fs.Dir) error {
+	return templates.ErrReadOnly
+}
+
+func (r *GitRepo) RemoveTemplate(string) error {
+	return templates.ErrReadOnly
+}
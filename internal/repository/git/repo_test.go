@@ -0,0 +1,133 @@
+package git_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/git"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{
+		"-C", dir,
+		"-c", "user.name=flow",
+		"-c", "user.email=flow@example.com",
+		"-c", "init.defaultBranch=main",
+	}, args...)...)
+
+	out, err := cmd.CombinedOutput()
+	assert.NilError(t, err, string(out))
+
+	return string(out)
+}
+
+func commit(t *testing.T, dir string, message string) string {
+	t.Helper()
+	run(t, dir, "add", "-A")
+	run(t, dir, "commit", "--quiet", "-m", message)
+
+	out := run(t, dir, "rev-parse", "HEAD")
+	return out[:len(out)-1]
+}
+
+func TestGitRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Parallel()
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	run(t, ".", "init", "--quiet", "--bare", remote)
+
+	work := fs.NewDir(t, "work",
+		fs.WithDir("templates",
+			fs.WithDir("button", fs.WithFile("index.ts", "v1")),
+		),
+	)
+	run(t, work.Path(), "init", "--quiet")
+	first := commit(t, work.Path(), "v1")
+	run(t, work.Path(), "push", "--quiet", remote, "HEAD:main")
+
+	cache := t.TempDir()
+	r := git.New(remote, "main", "templates", cache)
+	pinned := git.New(remote, first, "templates", cache)
+
+	names, err := r.GetTemplatesNames()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"button"})
+
+	fs.Apply(t, work, fs.WithDir("templates",
+		fs.WithDir("button", fs.WithFile("index.ts", "v2")),
+	))
+	second := commit(t, work.Path(), "v2")
+	run(t, work.Path(), "push", "--quiet", remote, "HEAD:main")
+
+	// Checkout is not changed until update
+	dir, _, err := r.GetTemplate("button")
+	assert.NilError(t, err)
+	assert.Equal(t, dir.Files[0].Source, "v1")
+
+	commitHash, err := r.Update()
+	assert.NilError(t, err)
+	assert.Equal(t, commitHash, second)
+
+	dir, _, err = r.GetTemplate("button")
+	assert.NilError(t, err)
+	assert.Equal(t, dir.Files[0].Source, "v2")
+
+	// Sources of one repository pinned to different refs do not share checkout
+	dir, _, err = pinned.GetTemplate("button")
+	assert.NilError(t, err)
+	assert.Equal(t, dir.Files[0].Source, "v1")
+
+	commitHash, err = pinned.Update()
+	assert.NilError(t, err)
+	assert.Equal(t, commitHash, first)
+
+	assert.ErrorIs(t, r.RemoveTemplate("button"), templates.ErrReadOnly)
+}
+
+func TestGitRepoFailedClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Parallel()
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	cache := t.TempDir()
+	r := git.New(remote, "main", "templates", cache)
+
+	_, err := r.GetTemplatesNames()
+	assert.ErrorContains(t, err, "failed to clone")
+
+	run(t, ".", "init", "--quiet", "--bare", remote)
+	work := fs.NewDir(t, "work",
+		fs.WithDir("templates",
+			fs.WithDir("button", fs.WithFile("index.ts", "v1")),
+		),
+	)
+	run(t, work.Path(), "init", "--quiet")
+	commit(t, work.Path(), "v1")
+	run(t, work.Path(), "push", "--quiet", remote, "HEAD:main")
+
+	// Failure is kept for the life of repo, so lookups do not clone again
+	_, err = r.GetTemplatesNames()
+	assert.ErrorContains(t, err, "failed to clone")
+
+	// New repo retries the clone
+	names, err := git.New(remote, "main", "templates", cache).GetTemplatesNames()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"button"})
+
+	_, err = r.Update()
+	assert.NilError(t, err)
+
+	names, err = r.GetTemplatesNames()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"button"})
+}
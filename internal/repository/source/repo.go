@@ -3,7 +3,8 @@ package source
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+
+	"github.com/flowtemplates/flow-cli/pkg/fs"
 )
 
 type SourceRepo struct{}
@@ -25,29 +26,40 @@ func (r SourceRepo) DirsExist(paths []string) error {
 	return nil
 }
 
-func (r SourceRepo) WriteFile(path string, source string) (string, error) {
-	dirPath := filepath.Dir(path)
-
-	err := os.MkdirAll(dirPath, 0o755)
+func (r SourceRepo) ReadFile(path string) (string, error) {
+	source, err := os.ReadFile(path)
 	if err != nil {
-		return "", fmt.Errorf("error creating directory: %w", err)
+		return "", fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
-	file, err := os.Create(path)
+	return string(source), nil
+}
+
+func (r SourceRepo) FileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// ReadTree reads directory or single file located in path.
+// A single file is returned as the only file of an unnamed root dir
+func (r SourceRepo) ReadTree(path string) (fs.Dir, error) {
+	info, err := os.Stat(path)
 	if err != nil {
-		return "", fmt.Errorf("error creating file: %w", err)
+		return fs.Dir{}, fmt.Errorf("path %s does not exist or cannot be accessed: %w", path, err)
 	}
-	defer file.Close()
 
-	_, err = file.WriteString(source)
-	if err != nil {
-		return "", fmt.Errorf("error writing to file: %w", err)
+	if info.IsDir() {
+		return fs.ReadDirTree(path)
 	}
 
-	return path, nil
-}
+	file, err := fs.ReadFile(path)
+	if err != nil {
+		return fs.Dir{}, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
 
-func (r SourceRepo) FileExists(path string) bool {
-	info, err := os.Stat(path)
-	return err == nil && !info.IsDir()
+	return fs.Dir{
+		Name:  ".",
+		Path:  ".",
+		Files: []fs.File{file},
+	}, nil
 }
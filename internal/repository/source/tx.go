@@ -0,0 +1,268 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+type writtenFile struct {
+	path string
+	// backup holds copy of previous version of overwritten file
+	backup string
+}
+
+// writeTx keeps track of everything done to the file system during WriteFiles,
+// so it can be undone
+type writeTx struct {
+	// staged maps destination path to temp file containing new content
+	staged  map[string]string
+	written []writtenFile
+	// createdDirs are in order of creation, parents before children
+	createdDirs []string
+	// backupDir is created outside of destination dirs on first overwrite,
+	// so hooks run on written files never see backups
+	backupDir string
+}
+
+// mkdirAll creates dir with all parents, remembering which of them did not exist
+func (tx *writeTx) mkdirAll(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	slices.Reverse(missing)
+	tx.createdDirs = append(tx.createdDirs, missing...)
+
+	return nil
+}
+
+// reserveTemp returns name of a new empty temp file located in dir
+func reserveTemp(dir string, pattern string) (string, error) {
+	tmp, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return tmp.Name(), tmp.Close()
+}
+
+func (tx *writeTx) stage(path string, file fs.File) error {
+	dir := filepath.Dir(path)
+	if err := tx.mkdirAll(dir); err != nil {
+		return err
+	}
+
+	tmp, err := reserveTemp(dir, ".flow-*")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	tx.staged[path] = tmp
+
+	// Temp file only reserves the name for a link
+	if file.IsLink() {
+		if err := os.Remove(tmp); err != nil {
+			return fmt.Errorf("error creating link: %w", err)
+		}
+	}
+
+	if file.Mode == 0 && !file.IsLink() {
+		// Keep permissions of overwritten file
+		if info, err := os.Stat(path); err == nil {
+			file.Mode = info.Mode().Perm()
+		}
+	}
+
+	if err := fs.WriteFile(tmp, file); err != nil {
+		return fmt.Errorf("error writing to file: %w", err)
+	}
+
+	return nil
+}
+
+// copyFile copies file or link located in src to dst keeping its permissions
+func copyFile(src string, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	file := fs.File{
+		Mode:   info.Mode().Perm(),
+		Binary: true,
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(src)
+		},
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if file.LinkTarget, err = os.Readlink(src); err != nil {
+			return err
+		}
+	}
+
+	return fs.WriteFile(dst, file)
+}
+
+// backup copies file located in path to backup dir and returns location of the copy
+func (tx *writeTx) backup(path string) (string, error) {
+	if tx.backupDir == "" {
+		dir, err := os.MkdirTemp("", "flow-backup-")
+		if err != nil {
+			return "", err
+		}
+		tx.backupDir = dir
+	}
+
+	backup := filepath.Join(tx.backupDir, strconv.Itoa(len(tx.written)))
+	if err := copyFile(path, backup); err != nil {
+		return "", err
+	}
+
+	return backup, nil
+}
+
+// restore moves copy of backup in place of path
+func restore(backup string, path string) error {
+	tmp, err := reserveTemp(filepath.Dir(path), ".flow-*")
+	if err != nil {
+		return err
+	}
+
+	// Temp file only reserves the name, backup may be a link
+	if err := os.Remove(tmp); err != nil {
+		return err
+	}
+
+	if err := copyFile(backup, tmp); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (tx *writeTx) commit(path string) error {
+	w := writtenFile{path: path}
+
+	if _, err := os.Lstat(path); err == nil {
+		backup, err := tx.backup(path)
+		if err != nil {
+			return fmt.Errorf("failed to back up file %s: %w", path, err)
+		}
+		w.backup = backup
+	}
+	// Recorded before moving new content in place, so the backup is restored on failure
+	tx.written = append(tx.written, w)
+
+	if err := os.Rename(tx.staged[path], path); err != nil {
+		return fmt.Errorf("failed to move file %s in place: %w", path, err)
+	}
+	delete(tx.staged, path)
+
+	return nil
+}
+
+// Rollback removes staged and created files, restores overwritten ones
+// and removes directories created by the transaction
+func (tx *writeTx) Rollback() error {
+	var errs []error
+
+	for _, tmp := range tx.staged {
+		if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	tx.staged = map[string]string{}
+
+	for _, w := range slices.Backward(tx.written) {
+		if w.backup != "" {
+			if err := restore(w.backup, w.path); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore %s from %s: %w", w.path, w.backup, err))
+			}
+		} else if err := os.Remove(w.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", w.path, err))
+		}
+	}
+	tx.written = nil
+
+	// Deepest first, children are created after their parents
+	for _, dir := range slices.Backward(tx.createdDirs) {
+		// Directory may already contain files not created by flow, keep it then
+		_ = os.Remove(dir)
+	}
+	tx.createdDirs = nil
+
+	// Backups not restored are kept for manual recovery
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return tx.removeBackups()
+}
+
+// Commit removes backups of overwritten files
+func (tx *writeTx) Commit() error {
+	tx.written = nil
+	tx.createdDirs = nil
+
+	return tx.removeBackups()
+}
+
+func (tx *writeTx) removeBackups() error {
+	if tx.backupDir == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(tx.backupDir); err != nil {
+		return fmt.Errorf("failed to remove backups: %w", err)
+	}
+	tx.backupDir = ""
+
+	return nil
+}
+
+// WriteFiles writes all files or none of them: content is staged in temp files
+// next to destinations and then moved in place, while overwritten files are copied
+// to backups in the system temp dir.
+// On any failure every change is undone. Returned transaction must be either
+// committed to remove backups or rolled back to undo the whole write
+func (r SourceRepo) WriteFiles(files map[string]fs.File) (fs.Transaction, error) {
+	tx := &writeTx{staged: make(map[string]string)}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if err := tx.stage(path, files[path]); err != nil {
+			return nil, errors.Join(err, tx.Rollback())
+		}
+	}
+
+	for _, path := range paths {
+		if err := tx.commit(path); err != nil {
+			return nil, errors.Join(err, tx.Rollback())
+		}
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,108 @@
+package source_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/source"
+	flowfs "github.com/flowtemplates/flow-cli/pkg/fs"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func newFiles(dir *fs.Dir) map[string]flowfs.File {
+	return map[string]flowfs.File{
+		dir.Join("existing.txt"):    {Source: "new"},
+		dir.Join("nested/new.txt"):  {Source: "created"},
+		dir.Join("nested/dev.sh"):   {Source: "#!/bin/sh\n", Mode: 0o755},
+		dir.Join("nested/link.txt"): {LinkTarget: "new.txt"},
+		dir.Join("nested/icon.bin"): {
+			Binary: true,
+			Open: func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("\x00\x01")), nil
+			},
+		},
+	}
+}
+
+func TestWriteFilesCommit(t *testing.T) {
+	t.Parallel()
+	dir := fs.NewDir(t, "write-files",
+		fs.WithFile("existing.txt", "old", fs.WithMode(0o600)),
+	)
+
+	// Manifest content is read once, so a new one is built for each comparison
+	expected := func() fs.Manifest {
+		return fs.Expected(t,
+			fs.WithFile("existing.txt", "new", fs.WithMode(0o600)),
+			fs.WithDir("nested", fs.WithMode(0o755),
+				fs.WithFile("new.txt", "created", fs.WithMode(0o644)),
+				fs.WithFile("dev.sh", "#!/bin/sh\n", fs.WithMode(0o755)),
+				fs.WithFile("icon.bin", "\x00\x01", fs.WithMode(0o644)),
+				fs.WithSymlink("link.txt", "new.txt"),
+			),
+		)
+	}
+
+	tx, err := source.New().WriteFiles(newFiles(dir))
+	assert.NilError(t, err)
+	// Neither temp files nor backups are left among written files before commit
+	assert.Assert(t, fs.Equal(dir.Path(), expected()))
+	assert.NilError(t, tx.Commit())
+
+	assert.Assert(t, fs.Equal(dir.Path(), expected()))
+}
+
+func TestWriteFilesRollback(t *testing.T) {
+	t.Parallel()
+	dir := fs.NewDir(t, "write-files-rollback",
+		fs.WithFile("existing.txt", "old", fs.WithMode(0o600)),
+	)
+
+	tx, err := source.New().WriteFiles(newFiles(dir))
+	assert.NilError(t, err)
+	assert.NilError(t, tx.Rollback())
+
+	assert.Assert(t, fs.Equal(dir.Path(), fs.Expected(t,
+		fs.WithFile("existing.txt", "old", fs.WithMode(0o600)),
+	)))
+}
+
+func TestWriteFilesFailure(t *testing.T) {
+	t.Parallel()
+	dir := fs.NewDir(t, "write-files-failure",
+		fs.WithFile("existing.txt", "old"),
+	)
+
+	_, err := source.New().WriteFiles(map[string]flowfs.File{
+		dir.Join("a/new.txt"): {Source: "created"},
+		// existing.txt is a file, so it cannot be a parent directory
+		dir.Join("existing.txt", "nested.txt"): {Source: "fails"},
+	})
+	assert.ErrorContains(t, err, "error creating directory")
+
+	entries, err := os.ReadDir(dir.Path())
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+
+	source, err := os.ReadFile(filepath.Join(dir.Path(), "existing.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(source), "old")
+}
+
+func TestWriteFilesRollbackSiblingDirs(t *testing.T) {
+	t.Parallel()
+	dir := fs.NewDir(t, "write-files-rollback-siblings")
+
+	tx, err := source.New().WriteFiles(map[string]flowfs.File{
+		dir.Join("a/b/c/x"): {Source: "x"},
+		dir.Join("a/b/d/y"): {Source: "y"},
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, tx.Rollback())
+
+	assert.Assert(t, fs.Equal(dir.Path(), fs.Expected(t)))
+}
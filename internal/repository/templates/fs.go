@@ -0,0 +1,61 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+var ErrReadOnly = errors.New("template source is read-only")
+
+// FSRepo serves templates from root directories of a read-only file system,
+// e.g. embed.FS or an archive
+type FSRepo struct {
+	fsys iofs.FS
+}
+
+func NewFS(fsys iofs.FS) *FSRepo {
+	return &FSRepo{
+		fsys: fsys,
+	}
+}
+
+func (r FSRepo) GetTemplatesNames() ([]string, error) {
+	entries, err := iofs.ReadDir(r.fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		// Hidden dirs such as .git are never templates
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// GetTemplate reads template dir and its manifest. Manifest file is excluded from returned dir,
+// template without manifest gets an empty one
+func (r FSRepo) GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error) {
+	dir, err := fs.ReadFSTree(r.fsys, templateName)
+	if err != nil {
+		return fs.Dir{}, nil, err
+	}
+
+	return SplitManifest(dir)
+}
+
+func (r FSRepo) CreateTemplate(string, fs.Dir) error {
+	return ErrReadOnly
+}
+
+func (r FSRepo) RemoveTemplate(string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,188 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+// Repo is a single source of templates
+type Repo interface {
+	GetTemplatesNames() ([]string, error)
+	GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error)
+	CreateTemplate(templateName string, dir fs.Dir) error
+	RemoveTemplate(templateName string) error
+}
+
+type Source struct {
+	Name string
+	Repo Repo
+}
+
+// sourceSeparator separates source name from template name in qualified names, e.g. "team:button"
+const sourceSeparator = ":"
+
+var ErrNoSources = errors.New("no template sources")
+
+// SourceError is an error of template source whose templates cannot be listed
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("source %s: %v", e.Source, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// MultiRepo searches templates in several sources in order of precedence.
+// Template shadowed by a source with higher precedence is available by qualified name <source>:<name>
+type MultiRepo struct {
+	sources []Source
+}
+
+func NewMulti(sources ...Source) *MultiRepo {
+	return &MultiRepo{
+		sources: sources,
+	}
+}
+
+func (r MultiRepo) sourceByName(name string) (Source, bool) {
+	i := slices.IndexFunc(r.sources, func(s Source) bool {
+		return s.Name == name
+	})
+	if i < 0 {
+		return Source{}, false
+	}
+
+	return r.sources[i], true
+}
+
+// resolve returns source of template and its unqualified name.
+// Sources that cannot be listed are skipped, unless template is qualified with one of them
+func (r MultiRepo) resolve(templateName string) (Source, string, error) {
+	if sourceName, name, ok := strings.Cut(templateName, sourceSeparator); ok {
+		source, ok := r.sourceByName(sourceName)
+		if !ok {
+			return Source{}, "", fmt.Errorf("unknown template source %s", sourceName)
+		}
+
+		return source, name, nil
+	}
+
+	for _, source := range r.sources {
+		names, err := source.Repo.GetTemplatesNames()
+		if err != nil {
+			continue
+		}
+
+		if slices.Contains(names, templateName) {
+			return source, templateName, nil
+		}
+	}
+
+	if len(r.sources) == 0 {
+		return Source{}, "", ErrNoSources
+	}
+
+	// Not existing template belongs to the source with the highest precedence
+	return r.sources[0], templateName, nil
+}
+
+// GetTemplatesNames returns names of all templates, qualifying the shadowed ones.
+// Broken or unreachable source does not hide templates of the others: it is skipped
+// and returned error joins a *SourceError for every such source
+func (r MultiRepo) GetTemplatesNames() ([]string, error) {
+	var (
+		res  []string
+		errs []error
+	)
+	seen := make(map[string]bool)
+
+	for _, source := range r.sources {
+		names, err := source.Repo.GetTemplatesNames()
+		if err != nil {
+			errs = append(errs, &SourceError{Source: source.Name, Err: err})
+			continue
+		}
+
+		for _, name := range names {
+			if seen[name] {
+				res = append(res, source.Name+sourceSeparator+name)
+			} else {
+				seen[name] = true
+				res = append(res, name)
+			}
+		}
+	}
+
+	return res, errors.Join(errs...)
+}
+
+func (r MultiRepo) HasTemplate(templateName string) (bool, error) {
+	source, name, err := r.resolve(templateName)
+	if err != nil {
+		return false, err
+	}
+
+	names, err := source.Repo.GetTemplatesNames()
+	if err != nil {
+		return false, fmt.Errorf("source %s: %w", source.Name, err)
+	}
+
+	return slices.Contains(names, name), nil
+}
+
+// GetTemplateSource returns name of the source template is taken from
+func (r MultiRepo) GetTemplateSource(templateName string) (string, error) {
+	source, _, err := r.resolve(templateName)
+	if err != nil {
+		return "", err
+	}
+
+	return source.Name, nil
+}
+
+func (r MultiRepo) GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error) {
+	source, name, err := r.resolve(templateName)
+	if err != nil {
+		return fs.Dir{}, nil, err
+	}
+
+	return source.Repo.GetTemplate(name)
+}
+
+// CreateTemplate creates template in the source it is qualified with, otherwise in the source
+// with the highest precedence. Template with the same name in other sources is shadowed, not reported
+func (r MultiRepo) CreateTemplate(templateName string, dir fs.Dir) error {
+	if strings.Contains(templateName, sourceSeparator) {
+		source, name, err := r.resolve(templateName)
+		if err != nil {
+			return err
+		}
+
+		return source.Repo.CreateTemplate(name, dir)
+	}
+
+	if len(r.sources) == 0 {
+		return ErrNoSources
+	}
+
+	return r.sources[0].Repo.CreateTemplate(templateName, dir)
+}
+
+func (r MultiRepo) RemoveTemplate(templateName string) error {
+	source, name, err := r.resolve(templateName)
+	if err != nil {
+		return err
+	}
+
+	return source.Repo.RemoveTemplate(name)
+}
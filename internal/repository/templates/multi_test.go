@@ -0,0 +1,123 @@
+package templates_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	flowfs "github.com/flowtemplates/flow-cli/pkg/fs"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestMultiRepo(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project",
+		fs.WithDir("button", fs.WithFile("index.ts", "project")),
+	)
+	team := fs.NewDir(t, "team",
+		fs.WithDir("button", fs.WithFile("index.ts", "team")),
+		fs.WithDir("hook", fs.WithFile("index.ts", "team")),
+	)
+
+	r := templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+		templates.Source{Name: "team", Repo: templates.New(team.Path())},
+	)
+
+	names, err := r.GetTemplatesNames()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"button", "team:button", "hook"})
+
+	tests := []struct {
+		name   string
+		source string
+		value  string
+	}{
+		{name: "button", source: "project", value: "project"},
+		{name: "team:button", source: "team", value: "team"},
+		{name: "hook", source: "team", value: "team"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			source, err := r.GetTemplateSource(tc.name)
+			assert.NilError(t, err)
+			assert.Equal(t, source, tc.source)
+
+			dir, _, err := r.GetTemplate(tc.name)
+			assert.NilError(t, err)
+			assert.Equal(t, dir.Files[0].Source, tc.value)
+		})
+	}
+
+	exists, err := r.HasTemplate("project:hook")
+	assert.NilError(t, err)
+	assert.Assert(t, !exists)
+
+	_, err = r.GetTemplateSource("unknown:hook")
+	assert.ErrorContains(t, err, "unknown template source unknown")
+}
+
+func TestMultiRepoBrokenSource(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project",
+		fs.WithDir("button", fs.WithFile("index.ts", "project")),
+	)
+
+	r := templates.NewMulti(
+		templates.Source{Name: "team", Repo: templates.New(project.Join("missing"))},
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+	)
+
+	names, err := r.GetTemplatesNames()
+	assert.DeepEqual(t, names, []string{"button"})
+
+	var sourceErr *templates.SourceError
+	assert.Assert(t, errors.As(err, &sourceErr))
+	assert.Equal(t, sourceErr.Source, "team")
+
+	dir, _, err := r.GetTemplate("button")
+	assert.NilError(t, err)
+	assert.Equal(t, dir.Files[0].Source, "project")
+
+	_, _, err = r.GetTemplate("team:button")
+	assert.Assert(t, err != nil)
+}
+
+func TestMultiRepoCreate(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project")
+	team := fs.NewDir(t, "team",
+		fs.WithDir("hook", fs.WithFile("index.ts", "team")),
+	)
+
+	r := templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+		templates.Source{Name: "team", Repo: templates.New(team.Path())},
+	)
+
+	// Template of a source with lower precedence is shadowed by the new one
+	assert.NilError(t, r.CreateTemplate("hook", flowfs.Dir{
+		Files: []flowfs.File{{Name: "index.ts", Path: "index.ts", Source: "project"}},
+	}))
+
+	names, err := r.GetTemplatesNames()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, names, []string{"hook", "team:hook"})
+
+	dir, _, err := r.GetTemplate("hook")
+	assert.NilError(t, err)
+	assert.Equal(t, dir.Files[0].Source, "project")
+
+	err = r.CreateTemplate("hook", flowfs.Dir{})
+	assert.ErrorContains(t, err, "template hook already exists")
+
+	assert.NilError(t, r.CreateTemplate("team:card", flowfs.Dir{
+		Files: []flowfs.File{{Name: "index.ts", Path: "index.ts", Source: "team"}},
+	}))
+	exists, err := r.HasTemplate("team:card")
+	assert.NilError(t, err)
+	assert.Assert(t, exists)
+}
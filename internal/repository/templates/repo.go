@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/flowtemplates/flow-cli/internal/manifest"
 	"github.com/flowtemplates/flow-cli/pkg/fs"
 )
 
@@ -18,58 +21,69 @@ func New(baseDir string) *TemplatesRepo {
 	}
 }
 
+// view returns read-only view of templates folder
+func (r TemplatesRepo) view() *FSRepo {
+	return NewFS(fs.DirFS(r.baseDir))
+}
+
 func (r TemplatesRepo) GetTemplatesNames() ([]string, error) {
-	var directories []string
-	files, err := os.ReadDir(r.baseDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read dir: %w", err)
+	return r.view().GetTemplatesNames()
+}
+
+func (r TemplatesRepo) GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error) {
+	return r.view().GetTemplate(templateName)
+}
+
+// SplitManifest removes manifest file from the root of template dir and parses it.
+// Template without manifest gets an empty one
+func SplitManifest(dir fs.Dir) (fs.Dir, *manifest.Manifest, error) {
+	i := slices.IndexFunc(dir.Files, func(f fs.File) bool {
+		return f.Name == manifest.FileName
+	})
+	if i < 0 {
+		return dir, &manifest.Manifest{}, nil
 	}
 
-	for _, file := range files {
-		if file.IsDir() {
-			directories = append(directories, file.Name())
-		}
+	m, err := manifest.Parse([]byte(dir.Files[i].Source))
+	if err != nil {
+		return fs.Dir{}, nil, fmt.Errorf("%s: %w", dir.Files[i].Path, err)
 	}
-	return directories, nil
+
+	dir.Files = slices.Delete(slices.Clone(dir.Files), i, i+1)
+
+	return dir, m, nil
 }
 
-func (r TemplatesRepo) readDirTree(templateName string, relBaseDirPath string) (fs.Dir, error) {
-	fullDirPath := filepath.Join(r.baseDir, templateName, relBaseDirPath)
+// validateName checks that template name is a single path element, so the template
+// cannot be created or removed outside of templates folder
+func validateName(templateName string) error {
+	if templateName == "" ||
+		strings.HasPrefix(templateName, ".") ||
+		strings.ContainsAny(templateName, `/\`) ||
+		templateName != filepath.Base(templateName) {
+		return fmt.Errorf("invalid template name %q, expected a single directory name not starting with a dot", templateName)
+	}
 
-	root := fs.Dir{Name: filepath.Base(relBaseDirPath), Path: filepath.Dir(relBaseDirPath)}
+	return nil
+}
 
-	entries, err := os.ReadDir(fullDirPath)
-	if err != nil {
-		return fs.Dir{}, err
+func (r TemplatesRepo) CreateTemplate(templateName string, dir fs.Dir) error {
+	if err := validateName(templateName); err != nil {
+		return err
 	}
 
-	for _, entry := range entries {
-		relPath := filepath.Join(relBaseDirPath, entry.Name())
-
-		if entry.IsDir() {
-			subDir, err := r.readDirTree(templateName, relPath)
-			if err != nil {
-				return fs.Dir{}, err
-			}
-			root.Dirs = append(root.Dirs, subDir)
-		} else {
-			full := filepath.Join(r.baseDir, templateName, relPath)
-			source, err := os.ReadFile(full)
-			if err != nil {
-				return fs.Dir{}, fmt.Errorf("failed to open file %s: %w", relPath, err)
-			}
-
-			root.Files = append(root.Files, fs.File{
-				Name:   entry.Name(),
-				Path:   relPath,
-				Source: string(source),
-			})
-		}
+	templateDir := filepath.Join(r.baseDir, templateName)
+	if _, err := os.Stat(templateDir); err == nil {
+		return fmt.Errorf("template %s already exists", templateName)
 	}
 
-	return root, nil
+	return fs.WriteDirTree(templateDir, dir)
 }
 
-func (r TemplatesRepo) GetTemplate(templateName string) (fs.Dir, error) {
-	return r.readDirTree(templateName, "")
+func (r TemplatesRepo) RemoveTemplate(templateName string) error {
+	if err := validateName(templateName); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Join(r.baseDir, templateName))
 }
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/source"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"github.com/flowtemplates/flow-cli/internal/service"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+	src := fs.NewDir(t, "src",
+		fs.WithDir("Button",
+			fs.WithFile("Button.tsx", "export const Button = () => <ButtonGroup />\n"),
+			fs.WithFile("styles.css", ".root {}\n"),
+		),
+	)
+	root := fs.NewDir(t, "root", fs.WithDir("templates"))
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(root.Join("templates"))},
+	), source.New(), nil)
+
+	err := s.Clone("button", src.Join("Button"), map[string]string{
+		"Button":      "name",
+		"ButtonGroup": "group",
+	})
+	assert.NilError(t, err)
+
+	assert.Assert(t, fs.Equal(root.Join("templates", "button"), fs.Expected(t,
+		fs.WithMode(0o755),
+		fs.WithFile("{{name}}.tsx.ft", "export const {{name}} = () => <{{group}} />\n", fs.WithMode(0o644)),
+		fs.WithFile("styles.css", ".root {}\n", fs.WithMode(0o644)),
+	)))
+
+	tests := []struct {
+		name         string
+		templateName string
+		replacements map[string]string
+		expected     string
+	}{
+		{name: "parent dir", templateName: "../escaped", expected: "invalid template name"},
+		{name: "nested", templateName: "a/b", expected: "invalid template name"},
+		{name: "hidden", templateName: ".git", expected: "invalid template name"},
+		{name: "dot dot", templateName: "..", expected: "invalid template name"},
+		{
+			name:         "variable with space",
+			templateName: "card",
+			replacements: map[string]string{"Button": "my name"},
+			expected:     `invalid variable "my name"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.Clone(tc.templateName, src.Join("Button"), tc.replacements)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+
+	// Nothing is written outside of templates folder
+	assert.Assert(t, fs.Equal(root.Path(), fs.Expected(t,
+		fs.WithDir("templates", fs.WithMode(0o755),
+			fs.WithDir("button", fs.WithMode(0o755),
+				fs.WithFile("{{name}}.tsx.ft", "export const {{name}} = () => <{{group}} />\n", fs.WithMode(0o644)),
+				fs.WithFile("styles.css", ".root {}\n", fs.WithMode(0o644)),
+			),
+		),
+	)))
+}
+
+func TestCloneTemplatize(t *testing.T) {
+	t.Parallel()
+	src := fs.NewDir(t, "src",
+		fs.WithFile("Button.tsx", "Button"),
+		fs.WithFile("view.ft", "Button"),
+		fs.WithFile("icon.bin", "\x00Button"),
+		fs.WithFile("plain.txt", "plain"),
+		fs.WithSymlink("link.tsx", "Button.tsx"),
+		fs.WithDir("Button", fs.WithFile("index.ts", "export * from './Button'")),
+	)
+	tmpl := fs.NewDir(t, "templates")
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(tmpl.Path())},
+	), source.New(), nil)
+
+	assert.NilError(t, s.Clone("button", src.Path(), map[string]string{"Button": "name"}))
+
+	assert.Assert(t, fs.Equal(tmpl.Join("button"), fs.Expected(t,
+		fs.WithMode(0o755),
+		// Changed files become templates, unless they already are
+		fs.WithFile("{{name}}.tsx.ft", "{{name}}", fs.WithMode(0o644)),
+		fs.WithFile("view.ft", "{{name}}", fs.WithMode(0o644)),
+		// Binary content and link targets are kept as is
+		fs.WithFile("icon.bin", "\x00Button", fs.WithMode(0o644)),
+		fs.WithFile("plain.txt", "plain", fs.WithMode(0o644)),
+		fs.WithSymlink("link.tsx", src.Join("Button.tsx")),
+		fs.WithDir("{{name}}", fs.WithMode(0o755),
+			fs.WithFile("index.ts.ft", "export * from './{{name}}'", fs.WithMode(0o644)),
+		),
+	)))
+}
+
+func TestCloneShadowing(t *testing.T) {
+	t.Parallel()
+	src := fs.NewDir(t, "src", fs.WithFile("README.md", "docs"))
+	project := fs.NewDir(t, "project")
+	builtin := fs.NewDir(t, "builtin", fs.WithDir("readme", fs.WithFile("README.md", "builtin")))
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+		templates.Source{Name: "builtin", Repo: templates.New(builtin.Path())},
+	), source.New(), nil)
+
+	// Template of another source does not prevent the clone, it is shadowed
+	assert.NilError(t, s.Clone("readme", src.Path(), nil))
+	assert.Assert(t, fs.Equal(project.Join("readme"), fs.Expected(t,
+		fs.WithMode(0o755),
+		fs.WithFile("README.md", "docs", fs.WithMode(0o644)),
+	)))
+
+	err := s.Clone("readme", src.Path(), nil)
+	assert.ErrorContains(t, err, "template readme already exists")
+}
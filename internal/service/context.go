@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+	"github.com/flowtemplates/flow-go/analyzer"
+	"github.com/flowtemplates/flow-go/lexer"
+	"github.com/flowtemplates/flow-go/token"
+	"github.com/flowtemplates/flow-go/types"
+)
+
+// Variable describes template variable: its type inferred from template files
+// combined with declaration from template manifest
+type Variable struct {
+	Type        types.Type `json:"type"`
+	Description string     `json:"description,omitempty"`
+	Default     *string    `json:"default,omitempty"`
+	Enum        []string   `json:"enum,omitempty"`
+	Pattern     string     `json:"pattern,omitempty"`
+	// File is a template file path in which variable is first used
+	File string `json:"-"`
+}
+
+// TemplateContext maps names of all variables available for template to their descriptions
+type TemplateContext map[string]Variable
+
+func newTemplateContext(dir fs.Dir, m *manifest.Manifest) (TemplateContext, error) {
+	tm := make(analyzer.TypeMap)
+	origins := make(map[string]string)
+	if err := getTypeMapFromDir(dir, tm, origins); err != nil {
+		return nil, err
+	}
+
+	enums := make(map[string][]string)
+	getEnumsFromDir(dir, enums)
+
+	tc := make(TemplateContext, len(tm))
+	for name, typ := range tm {
+		v := Variable{Type: typ, File: origins[name]}
+		if typ != types.Boolean {
+			v.Enum = enums[name]
+		}
+		tc[name] = v
+	}
+
+	for name, decl := range m.Variables {
+		v, ok := tc[name]
+		if !ok {
+			// Declaration may come from a base template whose files were overridden
+			if len(m.Extends) > 0 {
+				continue
+			}
+
+			return nil, fmt.Errorf("%s: variable %s is not used in template", manifest.FileName, name)
+		}
+
+		if decl.Type != "" && (decl.Type == manifest.TypeBoolean) != (v.Type == types.Boolean) {
+			return nil, fmt.Errorf(
+				"%s: variable %s is declared as %s, but used as %v in %s",
+				manifest.FileName, name, decl.Type, v.Type, v.File,
+			)
+		}
+
+		v.Description = decl.Description
+		v.Default = decl.Default
+		// Values listed in manifest take precedence over those of switch cases
+		if len(decl.Enum) > 0 {
+			v.Enum = decl.Enum
+		}
+		v.Pattern = decl.Pattern
+		tc[name] = v
+	}
+
+	return tc, nil
+}
+
+// getEnumsFromDir collects values of variables compared in switch statements
+// of template files of dir into enums
+func getEnumsFromDir(dir fs.Dir, enums map[string][]string) {
+	for _, file := range dir.Files {
+		if isTemplateFile(file) && !file.Binary && !file.IsLink() {
+			mergeCaseEnums(lexer.TokensFromBytes([]byte(file.Source)), enums)
+		}
+	}
+
+	for _, d := range dir.Dirs {
+		getEnumsFromDir(d, enums)
+	}
+}
+
+// mergeCaseEnums adds values of variables compared in switch statements of tokens to enums,
+// e.g. "sm" and "lg" for size switched with cases "sm" and "lg". Cases belong to the closest
+// switch before them. Variable compared with anything but string literals has no fixed set
+// of values, its values are set to nil and stay nil
+func mergeCaseEnums(tokens []token.Token, enums map[string][]string) {
+	subject := ""
+
+	for i, t := range tokens {
+		switch {
+		case t.Val == "switch":
+			subject = ""
+			if i+1 < len(tokens) && identRe.MatchString(tokens[i+1].Val) {
+				subject = tokens[i+1].Val
+			}
+		case t.Kind == token.CASE && subject != "":
+			values := caseValues(tokens[i+1:])
+			existing, ok := enums[subject]
+			if values == nil || (ok && existing == nil) {
+				enums[subject] = nil
+				continue
+			}
+
+			for _, value := range values {
+				if !slices.Contains(existing, value) {
+					existing = append(existing, value)
+				}
+			}
+			enums[subject] = existing
+		}
+	}
+}
+
+// caseValues returns string literals listed right after case keyword, nil if case is not
+// followed by literals only
+func caseValues(tokens []token.Token) []string {
+	var values []string
+	for _, t := range tokens {
+		if t.Val == "," {
+			continue
+		}
+
+		value, err := strconv.Unquote(t.Val)
+		if err != nil {
+			break
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// withDefaults returns copy of scope with default values set for omitted variables
+func withDefaults(scope map[string]*string, tc TemplateContext) map[string]*string {
+	res := make(map[string]*string, len(scope))
+	for name, v := range scope {
+		res[name] = v
+	}
+
+	for name, v := range tc {
+		if _, ok := res[name]; !ok && v.Default != nil {
+			def := *v.Default
+			res[name] = &def
+		}
+	}
+
+	return res
+}
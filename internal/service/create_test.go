@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/source"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"github.com/flowtemplates/flow-cli/internal/service"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestCreateOverwritePolicy(t *testing.T) {
+	t.Parallel()
+	tmpl := fs.NewDir(t, "templates",
+		fs.WithDir("button",
+			fs.WithFile("existing.txt", "new"),
+			fs.WithFile("new.txt", "new"),
+		),
+	)
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(tmpl.Path())},
+	), source.New(), nil)
+
+	tests := []struct {
+		name      string
+		opts      service.CreateOptions
+		overwrite []string
+		expected  service.FileAction
+		content   string
+	}{
+		{
+			name:     "dry run force",
+			opts:     service.CreateOptions{DryRun: true, Overwrite: service.OverwriteAll},
+			expected: service.FileOverwrite,
+			content:  "old",
+		},
+		{
+			name:     "dry run skip existing",
+			opts:     service.CreateOptions{DryRun: true, Overwrite: service.OverwriteNone},
+			expected: service.FileSkip,
+			content:  "old",
+		},
+		{
+			// Interactive decision is not made in dry run
+			name:     "dry run ask",
+			opts:     service.CreateOptions{DryRun: true},
+			expected: service.FileOverwrite,
+			content:  "old",
+		},
+		{
+			name:     "force",
+			opts:     service.CreateOptions{Overwrite: service.OverwriteAll},
+			expected: service.FileOverwrite,
+			content:  "new",
+		},
+		{
+			name:     "skip existing",
+			opts:     service.CreateOptions{Overwrite: service.OverwriteNone},
+			expected: service.FileSkip,
+			content:  "old",
+		},
+		{
+			name:     "ask declined",
+			opts:     service.CreateOptions{},
+			expected: service.FileSkip,
+			content:  "old",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out := fs.NewDir(t, "out", fs.WithFile("existing.txt", "old"))
+
+			var asked bool
+			plan, err := s.Create("button", nil, func(files []service.Overwrite) ([]string, error) {
+				asked = true
+				return nil, nil
+			}, tc.opts, out.Path())
+			assert.NilError(t, err)
+			assert.Equal(t, asked, tc.opts.Overwrite == service.OverwriteAsk && !tc.opts.DryRun)
+
+			assert.DeepEqual(t, plan, []service.PlannedFile{
+				{Path: out.Join("existing.txt"), Action: tc.expected},
+				{Path: out.Join("new.txt"), Action: service.FileCreate},
+			})
+
+			expected := []fs.PathOp{fs.WithFile("existing.txt", tc.content)}
+			if !tc.opts.DryRun {
+				expected = append(expected, fs.WithFile("new.txt", "new"))
+			}
+			assert.Assert(t, fs.Equal(out.Path(), fs.Expected(t, expected...)))
+		})
+	}
+}
+
+func TestCreateDryRun(t *testing.T) {
+	t.Parallel()
+	tmpl := fs.NewDir(t, "templates",
+		fs.WithDir("button",
+			fs.WithFile("template.yaml", "hooks:\n  preGenerate: [pre]\n  postGenerate: [post]\n"),
+			fs.WithFile("index.ts", "index"),
+			fs.WithDir("styles", fs.WithFile("button.css", "css")),
+		),
+	)
+	out := fs.NewDir(t, "out", fs.WithDir("a"), fs.WithDir("b", fs.WithFile("index.ts", "old")))
+
+	hr := &fakeHookRunner{}
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(tmpl.Path())},
+	), source.New(), hr)
+
+	plan, err := s.Create("button", nil, nil, service.CreateOptions{DryRun: true}, out.Join("a"), out.Join("b"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, plan, []service.PlannedFile{
+		{Path: out.Join("a", "index.ts"), Action: service.FileCreate},
+		{Path: out.Join("a", "styles", "button.css"), Action: service.FileCreate},
+		{Path: out.Join("b", "index.ts"), Action: service.FileOverwrite},
+		{Path: out.Join("b", "styles", "button.css"), Action: service.FileCreate},
+	})
+
+	// Nothing is written and no hook is run
+	assert.Assert(t, hr.calls == nil)
+	assert.Assert(t, fs.Equal(out.Path(), fs.Expected(t,
+		fs.WithDir("a", fs.WithMode(0o755)),
+		fs.WithDir("b", fs.WithMode(0o755), fs.WithFile("index.ts", "old")),
+	)))
+}
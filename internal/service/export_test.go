@@ -0,0 +1,10 @@
+package service
+
+// Internals exported for tests of service_test package
+var (
+	Typecheck      = typecheck
+	MergeTypeMap   = mergeTypeMap
+	MergeDirs      = mergeDirs
+	MergeManifests = mergeManifests
+	MergeCaseEnums = mergeCaseEnums
+)
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/flowtemplates/flow-go/renderer"
+)
+
+// HookError is returned when hook command fails
+type HookError struct {
+	Command string
+	Dir     string
+	Err     error
+}
+
+func (e HookError) Error() string {
+	return fmt.Sprintf("hook failed in %s: %s", e.Dir, e.Err)
+}
+
+func (e HookError) Unwrap() error {
+	return e.Err
+}
+
+func hookEnv(templateName string, scope renderer.Scope) []string {
+	env := []string{"FLOW_TEMPLATE=" + templateName}
+	for name, v := range scope {
+		env = append(env, fmt.Sprintf("FLOW_VAR_%s=%s", name, v))
+	}
+	slices.Sort(env[1:])
+
+	return env
+}
+
+// runHooks runs every command in every output dir, stopping on the first failure
+func (s Service) runHooks(commands []string, outputs []string, env []string, out io.Writer) error {
+	if out == nil {
+		out = io.Discard
+	}
+
+	for _, dir := range outputs {
+		dirEnv := append(slices.Clone(env), "FLOW_OUTPUT="+dir)
+		for _, command := range commands {
+			if err := s.hr.Run(command, dir, dirEnv, out); err != nil {
+				return HookError{Command: command, Dir: dir, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/repository/source"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"github.com/flowtemplates/flow-cli/internal/service"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+type hookCall struct {
+	Command string
+	Dir     string
+}
+
+// fakeHookRunner records commands instead of running them, command "fail" fails
+type fakeHookRunner struct {
+	calls []hookCall
+}
+
+func (r *fakeHookRunner) Run(command string, dir string, env []string, _ io.Writer) error {
+	if !slices.Contains(env, "FLOW_OUTPUT="+dir) || !slices.Contains(env, "FLOW_TEMPLATE=button") {
+		return errors.New("unexpected env: " + strings.Join(env, " "))
+	}
+
+	r.calls = append(r.calls, hookCall{Command: command, Dir: dir})
+	if command == "fail" {
+		return errors.New("exit status 1")
+	}
+
+	return nil
+}
+
+func TestCreateHooks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		manifest string
+		noHooks  bool
+		expected []hookCall
+		failed   bool
+		written  bool
+	}{
+		{
+			// Hooks of base run first, every hook runs in every output before the next phase
+			name: "order",
+			manifest: "extends: [base]\n" +
+				"hooks:\n  preGenerate: [pre]\n  postGenerate: [post]\n",
+			expected: []hookCall{
+				{"base-pre", "a"}, {"pre", "a"},
+				{"base-pre", "b"}, {"pre", "b"},
+				{"base-post", "a"}, {"post", "a"},
+				{"base-post", "b"}, {"post", "b"},
+			},
+			written: true,
+		},
+		{
+			name: "no hooks",
+			manifest: "extends: [base]\n" +
+				"hooks:\n  preGenerate: [pre]\n",
+			noHooks:  true,
+			expected: nil,
+			written:  true,
+		},
+		{
+			name:     "pre-generate failure",
+			manifest: "hooks:\n  preGenerate: [fail, pre]\n",
+			expected: []hookCall{{"fail", "a"}},
+			failed:   true,
+			written:  false,
+		},
+		{
+			name:     "post-generate failure",
+			manifest: "hooks:\n  postGenerate: [fail]\n",
+			expected: []hookCall{{"fail", "a"}},
+			failed:   true,
+			written:  true,
+		},
+		{
+			name:     "post-generate failure with rollback",
+			manifest: "hooks:\n  postGenerate: [fail]\n  rollbackOnFailure: true\n",
+			expected: []hookCall{{"fail", "a"}},
+			failed:   true,
+			written:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tmpl := fs.NewDir(t, "templates",
+				fs.WithDir("base",
+					fs.WithFile("template.yaml", "hooks:\n  preGenerate: [base-pre]\n  postGenerate: [base-post]\n"),
+					fs.WithFile("base.txt", "base"),
+				),
+				fs.WithDir("button",
+					fs.WithFile("template.yaml", tc.manifest),
+					fs.WithFile("button.txt", "button"),
+				),
+			)
+			out := fs.NewDir(t, "out", fs.WithDir("a"), fs.WithDir("b"))
+
+			hr := &fakeHookRunner{}
+			s := service.New(templates.NewMulti(
+				templates.Source{Name: "project", Repo: templates.New(tmpl.Path())},
+			), source.New(), hr)
+
+			_, err := s.Create("button", nil, nil, service.CreateOptions{NoHooks: tc.noHooks}, out.Join("a"), out.Join("b"))
+			if tc.failed {
+				var hookErr service.HookError
+				assert.Assert(t, errors.As(err, &hookErr))
+				assert.Equal(t, hookErr.Dir, out.Join("a"))
+			} else {
+				assert.NilError(t, err)
+			}
+
+			for i := range tc.expected {
+				tc.expected[i].Dir = out.Join(tc.expected[i].Dir)
+			}
+			assert.DeepEqual(t, hr.calls, tc.expected)
+
+			files := []fs.PathOp{fs.WithMode(0o755)}
+			if tc.written {
+				files = append(files, fs.WithFile("button.txt", "button"))
+				if strings.Contains(tc.manifest, "extends") {
+					files = append(files, fs.WithFile("base.txt", "base"))
+				}
+			}
+			assert.Assert(t, fs.Equal(out.Join("a"), fs.Expected(t, files...)))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/pkg/fs"
+)
+
+// outputName returns name of the file after generation, so "index.ts.ft" overrides "index.ts"
+func outputName(file fs.File) string {
+	return strings.TrimSuffix(file.Name, templateFileExt)
+}
+
+// mergeDirs merges child into base by path, files of child win
+func mergeDirs(base fs.Dir, child fs.Dir) fs.Dir {
+	res := fs.Dir{
+		Name: child.Name,
+		Path: child.Path,
+	}
+
+	for _, f := range base.Files {
+		if !slices.ContainsFunc(child.Files, func(c fs.File) bool {
+			return outputName(c) == outputName(f)
+		}) {
+			res.Files = append(res.Files, f)
+		}
+	}
+	res.Files = append(res.Files, child.Files...)
+
+	res.Dirs = slices.Clone(base.Dirs)
+	for _, d := range child.Dirs {
+		i := slices.IndexFunc(res.Dirs, func(b fs.Dir) bool {
+			return b.Name == d.Name
+		})
+		if i < 0 {
+			res.Dirs = append(res.Dirs, d)
+		} else {
+			res.Dirs[i] = mergeDirs(res.Dirs[i], d)
+		}
+	}
+
+	return res
+}
+
+// mergeManifests merges child into base: variables of child win, hooks of base run first
+func mergeManifests(base *manifest.Manifest, child *manifest.Manifest) *manifest.Manifest {
+	res := &manifest.Manifest{
+		Extends:     child.Extends,
+		Description: base.Description,
+		Tags:        base.Tags,
+		Variables:   make(map[string]manifest.Variable, len(base.Variables)+len(child.Variables)),
+		Hooks: manifest.Hooks{
+			PreGenerate:       slices.Concat(base.Hooks.PreGenerate, child.Hooks.PreGenerate),
+			PostGenerate:      slices.Concat(base.Hooks.PostGenerate, child.Hooks.PostGenerate),
+			RollbackOnFailure: base.Hooks.RollbackOnFailure || child.Hooks.RollbackOnFailure,
+		},
+	}
+
+	if child.Description != "" {
+		res.Description = child.Description
+	}
+	if len(child.Tags) > 0 {
+		res.Tags = child.Tags
+	}
+
+	for name, v := range base.Variables {
+		res.Variables[name] = v
+	}
+	for name, v := range child.Variables {
+		res.Variables[name] = v
+	}
+
+	return res
+}
+
+// baseTemplate is a template read without merging templates it extends
+type baseTemplate struct {
+	qualifiedName string
+	dir           fs.Dir
+	manifest      *manifest.Manifest
+}
+
+// resolveTemplate reads template and merges every template it extends into it.
+// Bases are looked up in the source of template unless qualified with another source
+func (s Service) resolveTemplate(templateName string) (fs.Dir, *manifest.Manifest, error) {
+	bases, err := s.linearize(templateName, nil, nil)
+	if err != nil {
+		return fs.Dir{}, nil, err
+	}
+
+	dir, m := bases[0].dir, bases[0].manifest
+	for _, b := range bases[1:] {
+		dir = mergeDirs(dir, b.dir)
+		m = mergeManifests(m, b.manifest)
+	}
+
+	return dir, m, nil
+}
+
+// linearize appends template to res after all templates it extends, in the order they are listed.
+// Template extended by several bases is appended once, so its files and hooks are merged once.
+// chain holds names of templates being resolved to detect cycles
+func (s Service) linearize(templateName string, chain []string, res []baseTemplate) ([]baseTemplate, error) {
+	source, err := s.tr.GetTemplateSource(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template source: %w", err)
+	}
+
+	// Chain holds qualified names, so cycle is found whatever name template was requested by
+	qualifiedName := templateName
+	if !strings.Contains(templateName, ":") {
+		qualifiedName = source + ":" + templateName
+	}
+
+	if slices.Contains(chain, qualifiedName) {
+		return nil, fmt.Errorf(
+			"cyclic extends: %s -> %s",
+			strings.Join(chain, " -> "), qualifiedName,
+		)
+	}
+
+	if slices.ContainsFunc(res, func(b baseTemplate) bool {
+		return b.qualifiedName == qualifiedName
+	}) {
+		return res, nil
+	}
+
+	dir, m, err := s.tr.GetTemplate(templateName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %s: %w", templateName, err)
+	}
+
+	chain = append(slices.Clone(chain), qualifiedName)
+	for _, baseName := range m.Extends {
+		if !strings.Contains(baseName, ":") {
+			baseName = source + ":" + baseName
+		}
+
+		if res, err = s.linearize(baseName, chain, res); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(res, baseTemplate{qualifiedName: qualifiedName, dir: dir, manifest: m}), nil
+}
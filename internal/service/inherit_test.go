@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/manifest"
+	"github.com/flowtemplates/flow-cli/internal/repository/archive"
+	"github.com/flowtemplates/flow-cli/internal/repository/source"
+	"github.com/flowtemplates/flow-cli/internal/repository/templates"
+	"github.com/flowtemplates/flow-cli/internal/service"
+	flowfs "github.com/flowtemplates/flow-cli/pkg/fs"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+// files maps paths of files in dir to their content
+func files(dir flowfs.Dir) map[string]string {
+	res := make(map[string]string)
+	for _, f := range dir.Files {
+		res[f.Path] = f.Source
+	}
+	for _, d := range dir.Dirs {
+		for path, content := range files(d) {
+			res[path] = content
+		}
+	}
+
+	return res
+}
+
+func TestExtendsMultiSource(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project",
+		fs.WithDir("base", fs.WithFile("base.ts", "project")),
+	)
+	team := fs.NewDir(t, "team",
+		fs.WithDir("base", fs.WithFile("base.ts", "team")),
+		fs.WithDir("button",
+			fs.WithFile("template.yaml", "extends: [base]\n"),
+			fs.WithFile("button.ts", "button"),
+		),
+		fs.WithDir("card",
+			fs.WithFile("template.yaml", "extends: [project:base]\n"),
+		),
+	)
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+		templates.Source{Name: "team", Repo: templates.New(team.Path())},
+	), source.New(), nil)
+
+	tests := []struct {
+		name     string
+		expected map[string]string
+	}{
+		{
+			// Base is looked up in the source of template, not by precedence
+			name:     "button",
+			expected: map[string]string{"base.ts": "team", "button.ts": "button"},
+		},
+		{
+			name:     "card",
+			expected: map[string]string{"base.ts": "project"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			dir, err := s.Bundle(tc.name)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, files(dir), tc.expected)
+		})
+	}
+}
+
+func TestExtendsCycle(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project",
+		fs.WithDir("a", fs.WithFile("template.yaml", "extends: [b]\n")),
+		fs.WithDir("b", fs.WithFile("template.yaml", "extends: [project:a]\n")),
+	)
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+	), source.New(), nil)
+
+	_, err := s.Bundle("a")
+	assert.Error(t, err, "cyclic extends: project:a -> project:b -> project:a")
+}
+
+func TestExtendsDiamond(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project",
+		fs.WithDir("base",
+			fs.WithFile("template.yaml", "hooks:\n  preGenerate: [base-pre]\n"),
+			fs.WithFile("index.ts", "base"),
+		),
+		fs.WithDir("left",
+			fs.WithFile("template.yaml", "extends: [base]\n"),
+			fs.WithFile("index.ts", "left"),
+		),
+		fs.WithDir("right",
+			fs.WithFile("template.yaml", "extends: [base]\n"),
+			fs.WithFile("right.ts", "right"),
+		),
+		fs.WithDir("button",
+			fs.WithFile("template.yaml", "extends: [left, project:right]\n"),
+		),
+	)
+	out := fs.NewDir(t, "out")
+
+	hr := &fakeHookRunner{}
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+	), source.New(), hr)
+
+	_, err := s.Create("button", nil, nil, service.CreateOptions{}, out.Path())
+	assert.NilError(t, err)
+
+	// Base shared by both parents is merged once, so right does not bring back its index.ts
+	assert.DeepEqual(t, hr.calls, []hookCall{{"base-pre", out.Path()}})
+	assert.Assert(t, fs.Equal(out.Path(), fs.Expected(t,
+		fs.WithFile("index.ts", "left"),
+		fs.WithFile("right.ts", "right"),
+	)))
+}
+
+func TestBundleArchive(t *testing.T) {
+	t.Parallel()
+	project := fs.NewDir(t, "project",
+		fs.WithDir("base",
+			fs.WithFile("template.yaml", "variables:\n  size:\n    description: Size\n"),
+			fs.WithFile("index.ts.ft", "{{size}}"),
+		),
+		fs.WithDir("button",
+			fs.WithFile("template.yaml", "extends: [base]\nvariables:\n  name:\n    description: Name\n"),
+			// Overrides the only file of base using size
+			fs.WithFile("index.ts.ft", "{{name}}"),
+		),
+	)
+
+	s := service.New(templates.NewMulti(
+		templates.Source{Name: "project", Repo: templates.New(project.Path())},
+	), source.New(), nil)
+
+	dir, err := s.Bundle("button")
+	assert.NilError(t, err)
+
+	path := filepath.Join(t.TempDir(), "button.tar.gz")
+	f, err := os.Create(path)
+	assert.NilError(t, err)
+	assert.NilError(t, archive.Write(f, archive.FormatTarGz, "button", dir))
+	assert.NilError(t, f.Close())
+
+	pack, err := archive.New("file://" + path)
+	assert.NilError(t, err)
+
+	packed := service.New(templates.NewMulti(
+		templates.Source{Name: "pack", Repo: pack},
+	), source.New(), nil)
+
+	tc, err := packed.GetTemplateContext("button")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, slices.Sorted(maps.Keys(tc)), []string{"name"})
+	assert.Equal(t, tc["name"].Description, "Name")
+}
+
+func TestMergeDirs(t *testing.T) {
+	t.Parallel()
+	base := flowfs.Dir{
+		Files: []flowfs.File{
+			{Name: "index.ts", Path: "index.ts", Source: "base"},
+			{Name: "README.md", Path: "README.md", Source: "base"},
+		},
+		Dirs: []flowfs.Dir{{
+			Name:  "styles",
+			Path:  ".",
+			Files: []flowfs.File{{Name: "a.css", Path: "styles/a.css", Source: "base"}},
+		}},
+	}
+	child := flowfs.Dir{
+		Files: []flowfs.File{
+			// Template file overrides the plain one it generates
+			{Name: "index.ts.ft", Path: "index.ts.ft", Source: "child"},
+		},
+		Dirs: []flowfs.Dir{{
+			Name: "styles",
+			Path: ".",
+			Files: []flowfs.File{
+				{Name: "a.css", Path: "styles/a.css", Source: "child"},
+				{Name: "b.css", Path: "styles/b.css", Source: "child"},
+			},
+		}},
+	}
+
+	assert.DeepEqual(t, files(service.MergeDirs(base, child)), map[string]string{
+		"index.ts.ft":  "child",
+		"README.md":    "base",
+		"styles/a.css": "child",
+		"styles/b.css": "child",
+	})
+}
+
+func TestMergeManifests(t *testing.T) {
+	t.Parallel()
+	base := &manifest.Manifest{
+		Description: "Base",
+		Tags:        []string{"base"},
+		Variables: map[string]manifest.Variable{
+			"name": {Description: "base"},
+			"size": {Description: "base"},
+		},
+		Hooks: manifest.Hooks{
+			PreGenerate:       []string{"base-pre"},
+			PostGenerate:      []string{"base-post"},
+			RollbackOnFailure: true,
+		},
+	}
+	child := &manifest.Manifest{
+		Extends: []string{"base"},
+		Variables: map[string]manifest.Variable{
+			"name": {Description: "child"},
+		},
+		Hooks: manifest.Hooks{
+			PostGenerate: []string{"post"},
+		},
+	}
+
+	assert.DeepEqual(t, service.MergeManifests(base, child), &manifest.Manifest{
+		Extends:     []string{"base"},
+		Description: "Base",
+		Tags:        []string{"base"},
+		Variables: map[string]manifest.Variable{
+			"name": {Description: "child"},
+			"size": {Description: "base"},
+		},
+		Hooks: manifest.Hooks{
+			PreGenerate:       []string{"base-pre"},
+			PostGenerate:      []string{"base-post", "post"},
+			RollbackOnFailure: true,
+		},
+	})
+}
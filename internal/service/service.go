@@ -3,137 +3,499 @@ package service
 import (
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/flowtemplates/flow-cli/internal/manifest"
 	"github.com/flowtemplates/flow-cli/pkg/fs"
 	"github.com/flowtemplates/flow-go/analyzer"
 	"github.com/flowtemplates/flow-go/renderer"
+	"github.com/flowtemplates/flow-go/types"
 )
 
 type templatesRepo interface {
 	GetTemplatesNames() ([]string, error)
-	GetTemplate(templateName string) (fs.Dir, error)
+	HasTemplate(templateName string) (bool, error)
+	GetTemplateSource(templateName string) (string, error)
+	GetTemplate(templateName string) (fs.Dir, *manifest.Manifest, error)
+	CreateTemplate(templateName string, dir fs.Dir) error
+	RemoveTemplate(templateName string) error
+}
+
+type hookRunner interface {
+	Run(command string, dir string, env []string, out io.Writer) error
 }
 
 type sourceRepo interface {
 	DirsExist(paths []string) error
-	WriteFile(path string, source string) (string, error)
+	WriteFiles(files map[string]fs.File) (fs.Transaction, error)
 	FileExists(path string) bool
+	ReadFile(path string) (string, error)
+	ReadTree(path string) (fs.Dir, error)
 }
 
+var ErrTemplateNotFound = errors.New("template not found")
+
 type Service struct {
 	tr templatesRepo
 	sr sourceRepo
+	hr hookRunner
 }
 
-func New(tr templatesRepo, sr sourceRepo) *Service {
+func New(tr templatesRepo, sr sourceRepo, hr hookRunner) *Service {
 	return &Service{
 		tr: tr,
 		sr: sr,
+		hr: hr,
+	}
+}
+
+type TemplateInfo struct {
+	Name        string   `json:"name"`
+	Source      string   `json:"source"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Variables is the number of variables of resolved template context
+	Variables int `json:"variables"`
+	// Files is the number of files generated by template, inherited ones included
+	Files int `json:"files"`
+	// ModTime is the latest modification time of template files, zero if unknown
+	ModTime time.Time `json:"modTime,omitzero"`
+	// Error is set if template cannot be loaded, other fields except Name and Source are empty then
+	Error string `json:"error,omitempty"`
+}
+
+// ListTemplates returns info of every template. Sources whose templates cannot be listed
+// are skipped: templates of the others are returned along with the error naming them
+func (s Service) ListTemplates() ([]TemplateInfo, error) {
+	templateNames, sourcesErr := s.tr.GetTemplatesNames()
+
+	res := make([]TemplateInfo, 0, len(templateNames))
+	for _, name := range templateNames {
+		source, err := s.tr.GetTemplateSource(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get template source: %w", err)
+		}
+
+		info := TemplateInfo{Name: name, Source: source}
+
+		// Broken template should not hide the others
+		t, err := s.loadTemplate(name)
+		if err != nil {
+			info.Error = err.Error()
+			res = append(res, info)
+			continue
+		}
+
+		info.Description = t.manifest.Description
+		info.Tags = t.manifest.Tags
+		info.Variables = len(t.context)
+		info.Files, info.ModTime = dirStats(t.dir)
+
+		res = append(res, info)
+	}
+
+	return res, sourcesErr
+}
+
+// dirStats returns number of files in dir and the latest modification time among them
+func dirStats(dir fs.Dir) (int, time.Time) {
+	count := len(dir.Files)
+
+	var modTime time.Time
+	for _, f := range dir.Files {
+		if f.ModTime.After(modTime) {
+			modTime = f.ModTime
+		}
+	}
+
+	for _, d := range dir.Dirs {
+		n, t := dirStats(d)
+		count += n
+		if t.After(modTime) {
+			modTime = t
+		}
 	}
+
+	return count, modTime
 }
 
-func (s Service) ListTemplates() ([]string, error) {
-	templateNames, err := s.tr.GetTemplatesNames()
+func (s Service) TemplateExists(templateName string) (bool, error) {
+	exists, err := s.tr.HasTemplate(templateName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get templates names: %w", err)
+		return false, fmt.Errorf("failed to find template: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (s Service) Remove(templateName string) error {
+	exists, err := s.TemplateExists(templateName)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, templateName)
+	}
+
+	if err := s.tr.RemoveTemplate(templateName); err != nil {
+		return fmt.Errorf("failed to remove template: %w", err)
 	}
 
-	return templateNames, nil
+	return nil
+}
+
+type FileAction string
+
+const (
+	FileCreate    FileAction = "create"
+	FileOverwrite FileAction = "overwrite"
+	FileSkip      FileAction = "skip"
+)
+
+// PlannedFile is an output file of Create and the action taken (or to be taken) on it
+type PlannedFile struct {
+	Path   string     `json:"path"`
+	Action FileAction `json:"action"`
+}
+
+// Overwrite is a request to replace existing file with newly rendered content
+type Overwrite struct {
+	Path     string
+	Existing string
+	Rendered string
+	// LinkTarget is set if file is replaced with a symbolic link
+	LinkTarget string
+	// Binary is set if file is replaced with binary content, Existing and Rendered are empty then
+	Binary bool
+}
+
+// OverwritePolicy decides which existing files Create replaces
+type OverwritePolicy int
+
+const (
+	// OverwriteAsk lets overwrite callback of Create decide. Dry run does not call it,
+	// files are planned to be overwritten then
+	OverwriteAsk OverwritePolicy = iota
+	// OverwriteAll replaces every existing file
+	OverwriteAll
+	// OverwriteNone keeps every existing file
+	OverwriteNone
+)
+
+type CreateOptions struct {
+	// DryRun makes Create only return planned files without writing anything
+	DryRun bool
+	// Overwrite is applied to existing files, dry run included
+	Overwrite OverwritePolicy
+	// NoHooks disables pre- and post-generate hooks declared by template
+	NoHooks bool
+	// HookOutput receives output of hooks
+	HookOutput io.Writer
 }
 
 func (s Service) Create(
 	templateName string,
 	scope map[string]*string,
-	overwriteFn func(files []string) ([]string, error),
+	overwriteFn func(files []Overwrite) ([]string, error),
+	opts CreateOptions,
 	outputs ...string,
-) error {
+) ([]PlannedFile, error) {
 	if len(outputs) < 1 {
-		return errors.New("at least one output required")
+		return nil, errors.New("at least one output required")
 	}
 
 	if err := s.sr.DirsExist(outputs); err != nil {
-		return err // nolint: wrapcheck
+		return nil, err // nolint: wrapcheck
 	}
 
-	templateDir, err := s.tr.GetTemplate(templateName)
+	t, err := s.loadTemplate(templateName)
 	if err != nil {
-		return fmt.Errorf("failed to get template: %w", err)
+		return nil, err
 	}
+	tc := t.context
 
-	tm := make(analyzer.TypeMap)
-	if err := getTypeMapFromDir(templateDir, tm); err != nil {
-		return err
+	scope = withDefaults(scope, tc)
+	if err := typecheck(scope, tc); err != nil {
+		return nil, err
 	}
 
 	sc := renderer.Scope{}
 	for n, v := range scope {
-		if v == nil {
+		switch {
+		case v == nil:
 			sc[n] = "true"
-		} else {
+		case tc[n].Type == types.Boolean && *v == "false":
+			// Disabled flag is the same as omitted one
+		default:
 			sc[n] = *v
 		}
 	}
 
-	// if err := analyzer.Typecheck(sc, tm, renderer.Context{}); err != nil {
-	// 	return fmt.Errorf("TypeErrors: %s", err)
-	// }
-
-	rendered, err := s.renderDir(templateDir, sc)
+	rendered, err := s.renderDir(t.dir, sc)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	renderedPaths := make([]string, 0, len(rendered))
+	for path := range rendered {
+		renderedPaths = append(renderedPaths, path)
 	}
+	slices.Sort(renderedPaths)
 
-	filesToWrite := make(map[string]string)
-	overwriteRequest := []string{}
+	filesToWrite := make(map[string]fs.File)
+	overwriteRequest := []Overwrite{}
+	plan := make([]PlannedFile, 0, len(outputs)*len(renderedPaths))
 
 	for _, dest := range outputs {
-		for path, source := range rendered {
+		for _, path := range renderedPaths {
 			destPath := filepath.Join(dest, path)
+			action := FileCreate
 			if s.sr.FileExists(destPath) {
-				overwriteRequest = append(overwriteRequest, destPath)
+				file := rendered[path]
+				var existing string
+				if !file.Binary {
+					existing, err = s.sr.ReadFile(destPath)
+					if err != nil {
+						return nil, err // nolint: wrapcheck
+					}
+				}
+
+				overwriteRequest = append(overwriteRequest, Overwrite{
+					Path:       destPath,
+					Existing:   existing,
+					Rendered:   file.Source,
+					LinkTarget: file.LinkTarget,
+					Binary:     file.Binary,
+				})
+				action = FileOverwrite
 			}
-			filesToWrite[destPath] = source
+			filesToWrite[destPath] = rendered[path]
+			plan = append(plan, PlannedFile{Path: destPath, Action: action})
 		}
 	}
 
 	if len(overwriteRequest) > 0 {
-		overwrite, err := overwriteFn(overwriteRequest)
+		overwrite, err := decideOverwrites(overwriteRequest, overwriteFn, opts)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		for _, initOverwrite := range overwriteRequest {
-			if !slices.Contains(overwrite, initOverwrite) {
-				delete(filesToWrite, initOverwrite)
+		for i, f := range plan {
+			if f.Action == FileOverwrite && !slices.Contains(overwrite, f.Path) {
+				delete(filesToWrite, f.Path)
+				plan[i].Action = FileSkip
 			}
 		}
 	}
 
-	for path, source := range filesToWrite {
-		_, err := s.sr.WriteFile(path, source)
-		if err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	var hooks manifest.Hooks
+	if !opts.NoHooks {
+		hooks = t.manifest.Hooks
+	}
+	env := hookEnv(templateName, sc)
+
+	if err := s.runHooks(hooks.PreGenerate, outputs, env, opts.HookOutput); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.sr.WriteFiles(filesToWrite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write files: %w", err)
+	}
+
+	if err := s.runHooks(hooks.PostGenerate, outputs, env, opts.HookOutput); err != nil {
+		if hooks.RollbackOnFailure {
+			return nil, errors.Join(err, tx.Rollback())
 		}
+
+		return nil, errors.Join(err, tx.Commit())
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to write files: %w", err)
+	}
+
+	return plan, nil
+}
+
+// decideOverwrites returns paths of existing files to be replaced according to overwrite policy
+func decideOverwrites(
+	files []Overwrite,
+	overwriteFn func(files []Overwrite) ([]string, error),
+	opts CreateOptions,
+) ([]string, error) {
+	all := make([]string, 0, len(files))
+	for _, f := range files {
+		all = append(all, f.Path)
+	}
+
+	switch {
+	case opts.Overwrite == OverwriteNone:
+		return nil, nil
+	case opts.Overwrite == OverwriteAll, opts.DryRun:
+		return all, nil
+	default:
+		return overwriteFn(files)
+	}
+}
+
+type template struct {
+	dir      fs.Dir
+	manifest *manifest.Manifest
+	context  TemplateContext
 }
 
-func (s Service) GetTemplateContext(templateName string) (analyzer.TypeMap, error) {
-	templateDir, err := s.tr.GetTemplate(templateName)
+func (s Service) loadTemplate(templateName string) (template, error) {
+	templateDir, m, err := s.resolveTemplate(templateName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get template: %w", err)
+		return template{}, err
 	}
 
-	tm := make(analyzer.TypeMap)
-	if err := getTypeMapFromDir(templateDir, tm); err != nil {
+	tc, err := newTemplateContext(templateDir, m)
+	if err != nil {
+		return template{}, err
+	}
+
+	return template{
+		dir:      templateDir,
+		manifest: m,
+		context:  tc,
+	}, nil
+}
+
+func (s Service) GetTemplateContext(templateName string) (TemplateContext, error) {
+	t, err := s.loadTemplate(templateName)
+	if err != nil {
 		return nil, err
 	}
 
-	return tm, nil
+	return t.context, nil
+}
+
+// Bundle returns self-contained template dir: inherited files are merged in
+// and resolved manifest is written back without extends
+func (s Service) Bundle(templateName string) (fs.Dir, error) {
+	t, err := s.loadTemplate(templateName)
+	if err != nil {
+		return fs.Dir{}, err
+	}
+
+	m := *t.manifest
+	m.Extends = nil
+	// Declarations of variables used only in overridden base files would
+	// make the bundled template invalid without extends
+	m.Variables = maps.Clone(m.Variables)
+	maps.DeleteFunc(m.Variables, func(name string, _ manifest.Variable) bool {
+		_, ok := t.context[name]
+		return !ok
+	})
+
+	data, err := manifest.Marshal(&m)
+	if err != nil {
+		return fs.Dir{}, err
+	}
+
+	dir := t.dir
+	if string(data) != "{}\n" {
+		dir.Files = append(slices.Clone(dir.Files), fs.File{
+			Name:   manifest.FileName,
+			Path:   manifest.FileName,
+			Source: string(data),
+		})
+	}
+
+	return dir, nil
+}
+
+// Clone creates template with templateName from directory or file located in path.
+// Every literal key of replacements found in file names and contents
+// is replaced with the variable it maps to. Template is created in the source
+// with the highest precedence unless qualified, shadowing templates of other sources
+func (s Service) Clone(templateName string, path string, replacements map[string]string) error {
+	for literal, variable := range replacements {
+		if !identRe.MatchString(variable) {
+			return fmt.Errorf("invalid variable %q to replace %q with, expected an identifier", variable, literal)
+		}
+	}
+
+	dir, err := s.sr.ReadTree(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	templateDir := templatizeDir(dir, newVarReplacer(replacements), "")
+
+	if err := s.tr.CreateTemplate(templateName, templateDir); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	return nil
+}
+
+// identRe matches valid variable names
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func newVarReplacer(replacements map[string]string) *strings.Replacer {
+	literals := make([]string, 0, len(replacements))
+	for literal := range replacements {
+		if literal != "" {
+			literals = append(literals, literal)
+		}
+	}
+
+	// Longer literals first, so "ButtonGroup" is not replaced as "Button" + "Group"
+	slices.SortFunc(literals, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+
+	oldnew := make([]string, 0, len(literals)*2)
+	for _, literal := range literals {
+		oldnew = append(oldnew, literal, fmt.Sprintf("{{%s}}", replacements[literal]))
+	}
+
+	return strings.NewReplacer(oldnew...)
+}
+
+func templatizeDir(dir fs.Dir, r *strings.Replacer, relPath string) fs.Dir {
+	res := fs.Dir{
+		Name: filepath.Base(relPath),
+		Path: filepath.Dir(relPath),
+	}
+
+	for _, d := range dir.Dirs {
+		name := r.Replace(d.Name)
+		res.Dirs = append(res.Dirs, templatizeDir(d, r, filepath.Join(relPath, name)))
+	}
+
+	for _, file := range dir.Files {
+		name := r.Replace(file.Name)
+		// Binary and link files have no Source, so they are never templated
+		source := r.Replace(file.Source)
+		if source != file.Source && !isTemplateFile(file) {
+			name += templateFileExt
+		}
+
+		file.Name = name
+		file.Path = filepath.Join(relPath, name)
+		file.Source = source
+		res.Files = append(res.Files, file)
+	}
+
+	return res
 }
 
 const templateFileExt = ".ft"
@@ -142,24 +504,24 @@ func isTemplateFile(file fs.File) bool {
 	return strings.HasSuffix(file.Name, templateFileExt)
 }
 
-func (s Service) renderDir(dir fs.Dir, scope renderer.Scope) (map[string]string, error) {
-	f := make(map[string]string)
-	if err := s.renderDirRecursive(dir, scope, f); err != nil {
+func (s Service) renderDir(dir fs.Dir, scope renderer.Scope) (map[string]fs.File, error) {
+	f := make(map[string]fs.File)
+	if err := s.renderDirRecursive(dir, "", scope, f); err != nil {
 		return nil, err
 	}
 
 	return f, nil
 }
 
-func (s Service) renderDirRecursive(dir fs.Dir, scope renderer.Scope, out map[string]string) error {
+// renderDirRecursive renders dir into out, where relPath is already rendered path of dir
+func (s Service) renderDirRecursive(dir fs.Dir, relPath string, scope renderer.Scope, out map[string]fs.File) error {
 	for _, d := range dir.Dirs {
 		dirName, err := renderer.RenderBytes([]byte(d.Name), scope)
 		if err != nil {
 			return fmt.Errorf("failed to render dirName: %w", err)
 		}
-		d.Name = dirName
 
-		if err := s.renderDirRecursive(d, scope, out); err != nil {
+		if err := s.renderDirRecursive(d, filepath.Join(relPath, dirName), scope, out); err != nil {
 			return err
 		}
 	}
@@ -170,39 +532,91 @@ func (s Service) renderDirRecursive(dir fs.Dir, scope renderer.Scope, out map[st
 			return fmt.Errorf("failed to render filename: %w", err)
 		}
 
-		content := file.Source
 		if isTemplateFile(file) {
-			content, err = renderer.RenderBytes([]byte(file.Source), scope)
-			if err != nil {
-				return fmt.Errorf("failed to render file %s: %w", filename, err)
+			// Binary content is copied as is even if it is named as template
+			if !file.Binary && !file.IsLink() {
+				file.Source, err = renderer.RenderBytes([]byte(file.Source), scope)
+				if err != nil {
+					return fmt.Errorf("failed to render file %s: %w", filename, err)
+				}
 			}
 			filename = strings.TrimSuffix(filename, templateFileExt)
 		}
 
-		out[filepath.Join(dir.Path, dir.Name, filename)] = content
+		file.Name = filename
+		file.Path = filepath.Join(relPath, filename)
+		out[file.Path] = file
 	}
 
 	return nil
 }
 
-func getTypeMapFromDir(dir fs.Dir, tm analyzer.TypeMap) error {
-	// for _, file := range dir.Files {
-	// if err := analyzer.TypeMapFromBytes(file.Name, tm); err != nil {
-	// 	return fmt.Errorf("failed to parse types in filename: %w", err)
-	// }
+// getTypeMapFromDir collects types of all variables used in dir into tm.
+// origins maps every variable to the path of the file it was first found in
+func getTypeMapFromDir(dir fs.Dir, tm analyzer.TypeMap, origins map[string]string) error {
+	for _, file := range dir.Files {
+		if err := mergeTypeMapFromBytes([]byte(file.Name), file.Path, tm, origins); err != nil {
+			return fmt.Errorf("failed to parse types in filename: %w", err)
+		}
 
-	// if isTemplateFile(file) {
-	// 	if err := analyzer.GetTypeMapFromString(file.Source, tm); err != nil {
-	// 		return fmt.Errorf("failed to parse types in file: %w", err)
-	// 	}
-	// }
-	// }
+		if isTemplateFile(file) && !file.Binary && !file.IsLink() {
+			if err := mergeTypeMapFromBytes([]byte(file.Source), file.Path, tm, origins); err != nil {
+				return fmt.Errorf("failed to parse types in file: %w", err)
+			}
+		}
+	}
 
 	for _, d := range dir.Dirs {
-		if err := getTypeMapFromDir(d, tm); err != nil {
+		if err := mergeTypeMapFromBytes([]byte(d.Name), filepath.Join(d.Path, d.Name), tm, origins); err != nil {
+			return fmt.Errorf("failed to parse types in dirname: %w", err)
+		}
+
+		if err := getTypeMapFromDir(d, tm, origins); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// mergeTypeMapFromBytes analyzes source separately and merges result into tm,
+// so the same variable inferred with different types in different files is reported
+func mergeTypeMapFromBytes(source []byte, path string, tm analyzer.TypeMap, origins map[string]string) error {
+	local := make(analyzer.TypeMap)
+	if err := analyzer.TypeMapFromBytes(source, local); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return mergeTypeMap(local, path, tm, origins)
+}
+
+// mergeTypeMap merges types of variables found in file located in path into tm
+func mergeTypeMap(local analyzer.TypeMap, path string, tm analyzer.TypeMap, origins map[string]string) error {
+	names := make([]string, 0, len(local))
+	for name := range local {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		typ := local[name]
+		prev, ok := tm[name]
+		if !ok {
+			tm[name] = typ
+			origins[name] = path
+			continue
+		}
+
+		if prev != typ {
+			return TypeConflictError{
+				Name:       name,
+				First:      prev,
+				FirstFile:  origins[name],
+				Second:     typ,
+				SecondFile: path,
+			}
+		}
+	}
+
+	return nil
+}
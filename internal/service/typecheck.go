@@ -0,0 +1,160 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/flowtemplates/flow-go/analyzer"
+	"github.com/flowtemplates/flow-go/renderer"
+	"github.com/flowtemplates/flow-go/types"
+)
+
+// TypeError describes single variable which value does not satisfy template context
+type TypeError struct {
+	Name     string
+	Expected types.Type
+	Value    string
+	// File is a template file path in which variable is used
+	File    string
+	Missing bool
+	Err     error
+}
+
+func (e TypeError) Error() string {
+	var msg string
+	switch {
+	case e.Missing:
+		msg = fmt.Sprintf("%s: missing value of type %v", e.Name, e.Expected)
+	case e.Err != nil:
+		msg = fmt.Sprintf("%s: value %q is invalid: %s", e.Name, e.Value, e.Err)
+	default:
+		msg = fmt.Sprintf("%s: value %q is not %v", e.Name, e.Value, e.Expected)
+	}
+
+	if e.File != "" {
+		msg += fmt.Sprintf(" (declared in %s)", e.File)
+	}
+
+	return msg
+}
+
+func (e TypeError) Unwrap() error {
+	return e.Err
+}
+
+// TypeErrors is returned when one or more variables fail type checking
+type TypeErrors []TypeError
+
+func (e TypeErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "type errors:\n  " + strings.Join(msgs, "\n  ")
+}
+
+// TypeConflictError is returned when variable is used with different types across template files
+type TypeConflictError struct {
+	Name       string
+	First      types.Type
+	FirstFile  string
+	Second     types.Type
+	SecondFile string
+}
+
+func (e TypeConflictError) Error() string {
+	return fmt.Sprintf(
+		"conflicting types of %s: %v in %s and %v in %s",
+		e.Name, e.First, e.FirstFile, e.Second, e.SecondFile,
+	)
+}
+
+var booleanValues = []string{"true", "false"}
+
+// typecheck checks every variable of tc against scope
+func typecheck(scope map[string]*string, tc TemplateContext) error {
+	names := make([]string, 0, len(tc))
+	for name := range tc {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs TypeErrors
+	for _, name := range names {
+		typ := tc[name].Type
+		file := tc[name].File
+		v, ok := scope[name]
+		// Omitted or valueless flag is fine, anything else requires a value
+		if !ok || v == nil {
+			if typ != types.Boolean {
+				errs = append(errs, TypeError{
+					Name:     name,
+					Expected: typ,
+					File:     file,
+					Missing:  true,
+				})
+			}
+			continue
+		}
+
+		if typ == types.Boolean {
+			if !slices.Contains(booleanValues, *v) {
+				errs = append(errs, TypeError{
+					Name:     name,
+					Expected: typ,
+					Value:    *v,
+					File:     file,
+				})
+			}
+			continue
+		}
+
+		if err := analyzer.Typecheck(
+			renderer.Scope{name: *v},
+			analyzer.TypeMap{name: typ},
+			renderer.Context{},
+		); err != nil {
+			errs = append(errs, TypeError{
+				Name:     name,
+				Expected: typ,
+				Value:    *v,
+				File:     file,
+				Err:      err,
+			})
+			continue
+		}
+
+		if enum := tc[name].Enum; len(enum) > 0 && !slices.Contains(enum, *v) {
+			errs = append(errs, TypeError{
+				Name:     name,
+				Expected: typ,
+				Value:    *v,
+				File:     file,
+				Err:      fmt.Errorf("must be one of: %s", strings.Join(enum, ", ")),
+			})
+			continue
+		}
+
+		if pattern := tc[name].Pattern; pattern != "" {
+			// Pattern is validated when manifest is parsed
+			if !regexp.MustCompile(pattern).MatchString(*v) {
+				errs = append(errs, TypeError{
+					Name:     name,
+					Expected: typ,
+					Value:    *v,
+					File:     file,
+					Err:      fmt.Errorf("does not match pattern %s", pattern),
+				})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/flowtemplates/flow-cli/internal/service"
+	"github.com/flowtemplates/flow-go/analyzer"
+	"github.com/flowtemplates/flow-go/token"
+	"github.com/flowtemplates/flow-go/types"
+	"gotest.tools/v3/assert"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestTypecheck(t *testing.T) {
+	t.Parallel()
+	context := service.TemplateContext{
+		"name":    {Type: types.String, File: "{{name}}.ts.ft"},
+		"flag":    {Type: types.Boolean, File: "index.ts.ft"},
+		"size":    {Type: types.String, Enum: []string{"sm", "lg"}},
+		"version": {Type: types.String, Pattern: `^\d+$`},
+	}
+
+	tests := []struct {
+		name     string
+		scope    map[string]*string
+		expected string
+	}{
+		{
+			name: "valid",
+			scope: map[string]*string{
+				"name":    ptr("button"),
+				"flag":    ptr("true"),
+				"size":    ptr("sm"),
+				"version": ptr("2"),
+			},
+		},
+		{
+			name: "flag without value",
+			scope: map[string]*string{
+				"name":    ptr("button"),
+				"flag":    nil,
+				"size":    ptr("lg"),
+				"version": ptr("1"),
+			},
+		},
+		{
+			name: "invalid values",
+			scope: map[string]*string{
+				"flag":    ptr("yes"),
+				"size":    ptr("md"),
+				"version": ptr("v1"),
+			},
+			expected: "type errors:\n" +
+				fmt.Sprintf(`  flag: value "yes" is not %v (declared in index.ts.ft)`, types.Boolean) + "\n" +
+				fmt.Sprintf("  name: missing value of type %v (declared in {{name}}.ts.ft)", types.String) + "\n" +
+				`  size: value "md" is invalid: must be one of: sm, lg` + "\n" +
+				`  version: value "v1" is invalid: does not match pattern ^\d+$`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := service.Typecheck(tc.scope, context)
+			if tc.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+
+			assert.Error(t, err, tc.expected)
+		})
+	}
+}
+
+func TestMergeTypeMap(t *testing.T) {
+	t.Parallel()
+	tm := analyzer.TypeMap{"name": types.String}
+	origins := map[string]string{"name": "{{name}}.ts.ft"}
+
+	err := service.MergeTypeMap(analyzer.TypeMap{
+		"name": types.String,
+		"flag": types.Boolean,
+	}, "index.ts.ft", tm, origins)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, tm, analyzer.TypeMap{"name": types.String, "flag": types.Boolean})
+	// Variable keeps the file it was first found in
+	assert.DeepEqual(t, origins, map[string]string{"name": "{{name}}.ts.ft", "flag": "index.ts.ft"})
+
+	err = service.MergeTypeMap(analyzer.TypeMap{"flag": types.String}, "README.md.ft", tm, origins)
+	assert.DeepEqual(t, err, error(service.TypeConflictError{
+		Name:       "flag",
+		First:      types.Boolean,
+		FirstFile:  "index.ts.ft",
+		Second:     types.String,
+		SecondFile: "README.md.ft",
+	}))
+}
+
+func TestMergeCaseEnums(t *testing.T) {
+	t.Parallel()
+	// Kind of tokens other than case does not matter
+	tok := func(val string) token.Token {
+		return token.Token{Kind: token.IF, Val: val}
+	}
+	caseTok := token.Token{Kind: token.CASE, Val: "case"}
+
+	enums := map[string][]string{"kind": {"a"}}
+	service.MergeCaseEnums([]token.Token{
+		tok("switch"), tok("size"),
+		caseTok, tok(`"sm"`), tok(","), tok(`"md"`), tok("}}"),
+		caseTok, tok(`"lg"`), tok(`"sm"`),
+		// Compared with a variable, so values of kind are not a fixed set
+		tok("switch"), tok("kind"),
+		caseTok, tok("other"),
+		caseTok, tok(`"b"`),
+		// Switch on expression
+		tok("switch"), tok("("),
+		caseTok, tok(`"x"`),
+	}, enums)
+
+	assert.DeepEqual(t, enums, map[string][]string{
+		"size": {"sm", "md", "lg"},
+		"kind": nil,
+	})
+}
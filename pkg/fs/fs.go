@@ -1,9 +1,55 @@
 package fs
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
 type File struct {
 	Name   string
 	Path   string
 	Source string
+	// Mode holds permission bits of the file, zero means default permissions
+	Mode os.FileMode
+	// ModTime is zero if file system does not track modification times, e.g. embed.FS
+	ModTime time.Time
+	// LinkTarget is set if file is a symbolic link, Source is empty then
+	LinkTarget string
+	// Binary files are not loaded into Source, their content is streamed from Open
+	Binary bool
+	Open   func() (io.ReadCloser, error)
+	// Size is the length of binary content
+	Size int64
+}
+
+func (f File) IsLink() bool {
+	return f.LinkTarget != ""
+}
+
+// sniffLen is the number of leading bytes checked to detect binary content
+const sniffLen = 8000
+
+// IsBinary reports whether head (leading bytes of the content) looks like binary data.
+// Like git, content containing NUL byte is considered binary
+func IsBinary(head []byte) bool {
+	return bytes.IndexByte(head[:min(len(head), sniffLen)], 0) >= 0
+}
+
+const DefaultFileMode os.FileMode = 0o644
+
+// Transaction is a completed write to file system which can still be undone
+type Transaction interface {
+	// Rollback undoes every change made by the write
+	Rollback() error
+	// Commit makes the write permanent
+	Commit() error
 }
 
 type Dir struct {
@@ -12,3 +58,272 @@ type Dir struct {
 	Files []File
 	Dirs  []Dir
 }
+
+// LinkFS is a file system able to read symbolic links.
+// Links of file systems not implementing it are followed
+type LinkFS interface {
+	iofs.FS
+	ReadLink(name string) (string, error)
+}
+
+type dirFS struct {
+	iofs.FS
+	dir string
+}
+
+// DirFS returns file system of directory dir which reads symbolic links instead of following them
+func DirFS(dir string) LinkFS {
+	return dirFS{
+		FS:  os.DirFS(dir),
+		dir: dir,
+	}
+}
+
+func (d dirFS) ReadLink(name string) (string, error) {
+	return os.Readlink(filepath.Join(d.dir, filepath.FromSlash(name))) // nolint: wrapcheck
+}
+
+// ReadDirTree reads directory dir with all nested dirs and files.
+// Paths of returned entries are relative to dir
+func ReadDirTree(dir string) (Dir, error) {
+	return ReadFSTree(DirFS(dir), ".")
+}
+
+// ReadFSTree reads directory root of fsys with all nested dirs and files.
+// Paths of returned entries are relative to root
+func ReadFSTree(fsys iofs.FS, root string) (Dir, error) {
+	return readFSTree(fsys, root, ".")
+}
+
+func readFSTree(fsys iofs.FS, root string, relPath string) (Dir, error) {
+	dir := Dir{Name: filepath.Base(relPath), Path: filepath.Dir(relPath)}
+
+	entries, err := iofs.ReadDir(fsys, path.Join(root, filepath.ToSlash(relPath)))
+	if err != nil {
+		return Dir{}, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		entryRelPath := filepath.Join(relPath, entry.Name())
+
+		if entry.IsDir() {
+			subDir, err := readFSTree(fsys, root, entryRelPath)
+			if err != nil {
+				return Dir{}, err
+			}
+			dir.Dirs = append(dir.Dirs, subDir)
+			continue
+		}
+
+		file, err := readFSFile(fsys, path.Join(root, filepath.ToSlash(entryRelPath)), entry)
+		if err != nil {
+			return Dir{}, fmt.Errorf("failed to open file %s: %w", entryRelPath, err)
+		}
+
+		file.Path = entryRelPath
+		dir.Files = append(dir.Files, file)
+	}
+
+	return dir, nil
+}
+
+func readFSFile(fsys iofs.FS, name string, entry iofs.DirEntry) (File, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return File{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	file := File{
+		Name:    entry.Name(),
+		Path:    entry.Name(),
+		Mode:    info.Mode().Perm(),
+		ModTime: info.ModTime(),
+	}
+
+	// Files of read-only file systems such as embed.FS are 0444,
+	// generated files should stay editable
+	file.Mode |= 0o200
+
+	if lfs, ok := fsys.(LinkFS); ok && info.Mode()&iofs.ModeSymlink != 0 {
+		file.LinkTarget, err = lfs.ReadLink(name)
+		if err != nil {
+			return File{}, fmt.Errorf("failed to read link: %w", err)
+		}
+
+		return file, nil
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	file.Source, file.Binary, err = ReadSource(f)
+	if err != nil {
+		return File{}, err
+	}
+
+	if file.Binary {
+		// Size of the opened file, as entry of a followed link describes the link
+		stat, err := f.Stat()
+		if err != nil {
+			return File{}, fmt.Errorf("failed to get file info: %w", err)
+		}
+		file.Size = stat.Size()
+
+		file.Open = func() (io.ReadCloser, error) {
+			return fsys.Open(name) // nolint: wrapcheck
+		}
+	}
+
+	return file, nil
+}
+
+// ReadFile reads file located in path without following symbolic links.
+// Path of returned file is equal to its name
+func ReadFile(path string) (File, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	file := File{
+		Name:    info.Name(),
+		Path:    info.Name(),
+		Mode:    info.Mode().Perm(),
+		ModTime: info.ModTime(),
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		file.LinkTarget, err = os.Readlink(path)
+		if err != nil {
+			return File{}, fmt.Errorf("failed to read link: %w", err)
+		}
+
+		return file, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	file.Source, file.Binary, err = ReadSource(f)
+	if err != nil {
+		return File{}, err
+	}
+
+	if file.Binary {
+		file.Size = info.Size()
+		file.Open = func() (io.ReadCloser, error) {
+			return os.Open(path) // nolint: wrapcheck
+		}
+	}
+
+	return file, nil
+}
+
+// ReadSource reads text content of r. If content looks binary, reading stops
+// right after detection and binary is true
+func ReadSource(r io.Reader) (string, bool, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", false, fmt.Errorf("failed to read content: %w", err)
+	}
+	head = head[:n]
+
+	if IsBinary(head) {
+		return "", true, nil
+	}
+
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read content: %w", err)
+	}
+
+	return string(head) + string(rest), false, nil
+}
+
+// WriteFile writes file to path, creating symbolic link for links
+func WriteFile(path string, file File) error {
+	if file.IsLink() {
+		if err := os.Symlink(file.LinkTarget, path); err != nil {
+			return fmt.Errorf("failed to create link: %w", err)
+		}
+
+		return nil
+	}
+
+	mode := file.Mode
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+
+	if err := writeContent(path, file, mode); err != nil {
+		return err
+	}
+
+	// Mode passed to os.OpenFile is affected by umask
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	return nil
+}
+
+func writeContent(path string, file File, mode os.FileMode) error {
+	if !file.Binary {
+		if err := os.WriteFile(path, []byte(file.Source), mode); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+
+		return nil
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open content: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteDirTree writes all files of dir to baseDir using their relative paths
+func WriteDirTree(baseDir string, dir Dir) error {
+	for _, d := range dir.Dirs {
+		if err := WriteDirTree(baseDir, d); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range dir.Files {
+		fullPath := filepath.Join(baseDir, file.Path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			return fmt.Errorf("error creating directory: %w", err)
+		}
+
+		if err := WriteFile(fullPath, file); err != nil {
+			return fmt.Errorf("error writing file %s: %w", file.Path, err)
+		}
+	}
+
+	return nil
+}